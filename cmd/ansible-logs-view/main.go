@@ -14,29 +14,133 @@ import (
 
 func main() {
 	debug := flag.Bool("debug", false, "Enable debug logging to debug.log")
+	noCache := flag.Bool("no-cache", false, "Don't read or write the parse index cache")
+	configPath := flag.String("config", "", "Path to the config file (default $XDG_CONFIG_HOME/ansible-logs-view/config.json)")
+	playbookID := flag.Int64("playbook", 0, "ID of the playbook to open from an ARA database")
+	follow := flag.Bool("follow", false, "Keep reading the log file as Ansible writes it")
+	exportPath := flag.String("export", "", "Write the tasks with secrets redacted to this file (- for stdout) instead of opening the TUI")
 	flag.Parse()
 
 	if len(flag.Args()) < 1 {
-		log.Fatal("Please provide a log file path as an argument")
+		log.Fatal("Please provide a log file, ansible-runner artifact directory, AWX job event export or ARA database as an argument")
 	}
 
 	filename := flag.Args()[0]
-	
+
+	// An explicitly given config file must exist, the default one is optional
+	required := *configPath != ""
+	if !required {
+		if path, err := app.DefaultConfigPath(); err == nil {
+			*configPath = path
+		}
+	}
+	config, err := app.LoadConfig(*configPath, required)
+	if err != nil {
+		log.Fatal(err)
+	}
+	redactor, err := app.NewRedactor(config.Redact)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	parser := app.NewLogParser(*debug)
-	tasks, err := parser.ParseFile(filename)
+	parser.SetRules(config.Rules)
+	defer parser.Close()
+	if !*noCache {
+		if dir, err := app.DefaultIndexCacheDir(); err == nil {
+			parser.SetIndexCacheDir(dir)
+		}
+	}
+	var tasks []app.Task
+	fromLog := false
+	switch {
+	case app.IsRunnerArtifactDir(filename):
+		tasks, err = app.ReadRunnerArtifacts(filename)
+	case app.IsAWXExport(filename):
+		tasks, err = app.ReadAWXExport(filename)
+	case app.IsARADatabase(filename):
+		tasks, err = readARA(filename, *playbookID)
+	default:
+		tasks, err = parser.ParseFile(filename)
+		fromLog = true
+	}
 	if err != nil {
 		log.Fatalf("Error parsing file: %v", err)
 	}
 
-	if len(tasks) == 0 {
+	if tasks == nil && app.IsARADatabase(filename) {
+		// No playbook chosen
+		return
+	}
+	if *follow && !fromLog {
+		log.Fatal("--follow only works with log files")
+	}
+	// A followed log may not have reached its first task yet
+	if len(tasks) == 0 && !*follow {
 		log.Fatal("No tasks found in the log file")
 	}
 
+	if *exportPath != "" {
+		if err := export(*exportPath, tasks, redactor); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Create and run TUI
 	m := app.NewModel(tasks, *debug)
+	m.SetRedactor(redactor)
+	if *follow {
+		m.SetFollow(parser.Follow)
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// readARA reads a playbook from an ARA database. Without an ID it opens the
+// only recorded playbook, or lets the user choose one of them. It returns no
+// tasks if the user quits without choosing.
+func readARA(path string, id int64) ([]app.Task, error) {
+	if id == 0 {
+		playbooks, err := app.ListARAPlaybooks(path)
+		if err != nil {
+			return nil, err
+		}
+		switch len(playbooks) {
+		case 0:
+			return nil, fmt.Errorf("no playbooks recorded in %s", path)
+		case 1:
+			id = playbooks[0].ID
+		default:
+			result, err := tea.NewProgram(app.NewARAPicker(playbooks), tea.WithAltScreen()).Run()
+			if err != nil {
+				return nil, fmt.Errorf("error running playbook picker: %v", err)
+			}
+			var chosen bool
+			if id, chosen = result.(app.ARAPicker).Chosen(); !chosen {
+				return nil, nil
+			}
+		}
+	}
+	return app.ReadARAPlaybook(path, id)
+}
+
+// export writes the tasks with secrets redacted to path, or to stdout if
+// path is "-".
+func export(path string, tasks []app.Task, redactor *app.Redactor) error {
+	if path == "-" {
+		return app.ExportTasks(os.Stdout, tasks, redactor)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating export: %v", err)
+	}
+	if err := app.ExportTasks(f, tasks, redactor); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiRegex matches ANSI escape sequences as written by Ansible with
+// ANSIBLE_FORCE_COLOR: CSI sequences such as colours ("\x1b[0;32m"), OSC
+// sequences such as hyperlinks, and two-byte escapes.
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;:?<=>]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// stripANSI removes all ANSI escape sequences from s.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiRegex.ReplaceAllString(s, "")
+}
+
+// ansiSafeCut moves cut back so that s[:cut] doesn't end inside an escape
+// sequence.
+func ansiSafeCut(s string, cut int) int {
+	i := strings.LastIndexByte(s[:cut], '\x1b')
+	if i < 0 {
+		return cut
+	}
+	if loc := ansiRegex.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 && i+loc[1] > cut {
+		return i
+	}
+	return cut
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"bytes"
+	"compress/zlib"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	// Pure Go driver, so the binary still builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// ARAPlaybook describes a playbook run recorded by ARA.
+type ARAPlaybook struct {
+	ID         int64
+	Name       string
+	Path       string
+	Status     string
+	Controller string
+	Started    time.Time
+	Duration   time.Duration
+}
+
+// araStatuses maps ARA result statuses to the statuses used for text logs,
+// where a failure is reported as "fatal" even when it is ignored.
+var araStatuses = map[string]string{
+	"ok":          "ok",
+	"changed":     "changed",
+	"failed":      "fatal",
+	"ignored":     "fatal",
+	"skipped":     "skipping",
+	"unreachable": "unreachable",
+}
+
+// IsARADatabase reports whether path is an SQLite database, such as the
+// ansible.sqlite written by ARA.
+func IsARADatabase(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return string(header) == "SQLite format 3\x00"
+}
+
+// openARADatabase opens an ARA database read-only.
+func openARADatabase(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+url.PathEscape(path)+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("error opening ARA database: %v", err)
+	}
+	return db, nil
+}
+
+// ListARAPlaybooks returns the playbooks recorded in an ARA database, most
+// recent first.
+func ListARAPlaybooks(path string) ([]ARAPlaybook, error) {
+	db, err := openARADatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT id, COALESCE(name, ''), path, status, controller, started, duration
+		FROM playbooks ORDER BY started DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ARA playbooks: %v", err)
+	}
+	defer rows.Close()
+
+	var playbooks []ARAPlaybook
+	for rows.Next() {
+		var pb ARAPlaybook
+		var started, duration any
+		if err := rows.Scan(&pb.ID, &pb.Name, &pb.Path, &pb.Status, &pb.Controller, &started, &duration); err != nil {
+			return nil, fmt.Errorf("error reading ARA playbooks: %v", err)
+		}
+		pb.Started = araTime(started)
+		pb.Duration = araDuration(duration)
+		playbooks = append(playbooks, pb)
+	}
+	return playbooks, rows.Err()
+}
+
+// ReadARAPlaybook turns the playbook with the given id recorded in an ARA
+// database into tasks with per-host results and timings.
+func ReadARAPlaybook(path string, id int64) ([]Task, error) {
+	db, err := openARADatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT t.id, COALESCE(t.name, ''), t.action, t.lineno, t.status, t.started, t.duration,
+			COALESCE(f.path, ''), COALESCE(p.name, '')
+		FROM tasks t
+		LEFT JOIN files f ON f.id = t.file_id
+		LEFT JOIN plays p ON p.id = t.play_id
+		WHERE t.playbook_id = ?
+		ORDER BY t.started, t.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ARA tasks: %v", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	taskIndex := make(map[int64]int) // ARA task id -> index in tasks
+	for rows.Next() {
+		var araID int64
+		var lineno int
+		var status, file string
+		var started, duration any
+		task := Task{ID: len(tasks) + 1}
+		if err := rows.Scan(&araID, &task.Description, &task.Module, &lineno, &status, &started, &duration, &file, &task.Play); err != nil {
+			return nil, fmt.Errorf("error reading ARA tasks: %v", err)
+		}
+		task.StartTime = araTime(started)
+		task.Duration = araDuration(duration)
+		task.Status = "unknown"
+		if file != "" {
+			task.Path = fmt.Sprintf("%s:%d", file, lineno)
+		}
+		if i := strings.Index(task.Description, " : "); i >= 0 {
+			task.Role = task.Description[:i]
+		}
+		taskIndex[araID] = len(tasks)
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks recorded for ARA playbook %d", id)
+	}
+
+	if err := readARAResults(db, id, tasks, taskIndex); err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].RawText = araRawText(&tasks[i])
+	}
+	debugLog.Printf("ReadARAPlaybook() - Read %d tasks of playbook %d from %s", len(tasks), id, path)
+	return tasks, nil
+}
+
+// readARAResults adds the host results of playbook id to their tasks.
+func readARAResults(db *sql.DB, id int64, tasks []Task, taskIndex map[int64]int) error {
+	rows, err := db.Query(`SELECT r.task_id, h.name, r.status, r.started, r.duration, r.content
+		FROM results r
+		JOIN hosts h ON h.id = r.host_id
+		WHERE r.playbook_id = ?
+		ORDER BY r.started, r.id`, id)
+	if err != nil {
+		return fmt.Errorf("error reading ARA results: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var host, status string
+		var started, duration any
+		var content []byte
+		if err := rows.Scan(&taskID, &host, &status, &started, &duration, &content); err != nil {
+			return fmt.Errorf("error reading ARA results: %v", err)
+		}
+		i, ok := taskIndex[taskID]
+		if !ok {
+			continue
+		}
+		task := &tasks[i]
+		if mapped, ok := araStatuses[status]; ok {
+			status = mapped
+		}
+		task.Status = status
+		task.Host = host
+		task.Hosts = append(task.Hosts, HostResult{
+			Host:      host,
+			Status:    status,
+			Module:    task.Module,
+			StartTime: araTime(started),
+			Duration:  araDuration(duration),
+		})
+		if res := decodeARAContent(content); res != nil {
+			data, _ := json.Marshal(res)
+			task.Hosts[len(task.Hosts)-1].Result = string(data)
+			applyResultPayload(task, len(task.Hosts)-1, res)
+		}
+	}
+	return rows.Err()
+}
+
+// decodeARAContent decodes a result's content column, zlib-compressed JSON.
+func decodeARAContent(content []byte) map[string]any {
+	reader, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+	var res map[string]any
+	if err := json.NewDecoder(reader).Decode(&res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// araRawText renders a task in the shape of Ansible's default output, as ARA
+// doesn't record the output itself.
+func araRawText(task *Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TASK [%s] ***\n", task.Description)
+	if task.Path != "" {
+		fmt.Fprintf(&b, "task path: %s\n", task.Path)
+	}
+	for _, result := range task.Hosts {
+		fmt.Fprintf(&b, "%s: [%s]\n", result.Status, result.Host)
+	}
+	return b.String()
+}
+
+// araTime converts a datetime column, which the driver returns either parsed
+// or as text depending on how it was stored.
+func araTime(value any) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case string:
+		return parseEventTime(v)
+	case []byte:
+		return parseEventTime(string(v))
+	}
+	return time.Time{}
+}
+
+// araDuration converts a duration column. Django stores durations in SQLite
+// as microseconds.
+func araDuration(value any) time.Duration {
+	switch v := value.(type) {
+	case int64:
+		return time.Duration(v) * time.Microsecond
+	case float64:
+		return time.Duration(v * float64(time.Microsecond))
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bytes"
+	"compress/zlib"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadARAPlaybook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ansible.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var content bytes.Buffer
+	zw := zlib.NewWriter(&content)
+	zw.Write([]byte(`{"changed": true, "invocation": {"module_args": {"dest": "/etc/motd"}}}`))
+	zw.Close()
+
+	statements := []string{
+		`CREATE TABLE playbooks (id integer PRIMARY KEY, name varchar(255), path varchar(255), status varchar(25),
+			controller varchar(255), started datetime, duration bigint)`,
+		`CREATE TABLE plays (id integer PRIMARY KEY, name varchar(255), playbook_id integer)`,
+		`CREATE TABLE files (id integer PRIMARY KEY, path text, playbook_id integer)`,
+		`CREATE TABLE tasks (id integer PRIMARY KEY, name text, action text, lineno integer, status varchar(25),
+			started datetime, duration bigint, file_id integer, play_id integer, playbook_id integer)`,
+		`CREATE TABLE hosts (id integer PRIMARY KEY, name varchar(255), playbook_id integer)`,
+		`CREATE TABLE results (id integer PRIMARY KEY, status varchar(25), started datetime, duration bigint,
+			content blob, host_id integer, task_id integer, playbook_id integer)`,
+		`INSERT INTO playbooks VALUES (1, NULL, '/srv/site.yml', 'completed', 'ctl01', '2025-10-28 14:20:31.000000', 5000000),
+			(2, 'older', '/srv/other.yml', 'failed', 'ctl01', '2025-10-27 09:00:00.000000', 1000000)`,
+		`INSERT INTO plays VALUES (1, 'Provision web servers', 1)`,
+		`INSERT INTO files VALUES (1, '/srv/roles/motd/tasks/main.yml', 1)`,
+		`INSERT INTO tasks VALUES (1, 'Gathering Facts', 'gather_facts', 2, 'completed', '2025-10-28 14:20:31.000000', 2000000, 1, 1, 1),
+			(2, 'motd : Write motd', 'copy', 4, 'completed', '2025-10-28 14:20:33.000000', 1500000, 1, 1, 1)`,
+		`INSERT INTO hosts VALUES (1, 'web01', 1), (2, 'web02', 1)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	_, err = db.Exec(`INSERT INTO results VALUES (1, 'ok', '2025-10-28 14:20:31.100000', 1000000, NULL, 1, 1, 1),
+		(2, 'ok', '2025-10-28 14:20:31.200000', 1800000, NULL, 2, 1, 1),
+		(3, 'changed', '2025-10-28 14:20:33.100000', 1200000, ?, 1, 2, 1),
+		(4, 'failed', '2025-10-28 14:20:33.200000', 1400000, NULL, 2, 2, 1)`, content.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsARADatabase(path) || IsARADatabase("../../testdata/sample-demo.log") {
+		t.Fatal("IsARADatabase misdetects paths")
+	}
+	playbooks, err := ListARAPlaybooks(path)
+	if err != nil {
+		t.Fatalf("ListARAPlaybooks: %v", err)
+	}
+	if len(playbooks) != 2 || playbooks[0].ID != 1 || playbooks[0].Path != "/srv/site.yml" || playbooks[0].Duration != 5*time.Second {
+		t.Fatalf("unexpected playbooks: %+v", playbooks)
+	}
+
+	tasks, err := ReadARAPlaybook(path, 1)
+	if err != nil {
+		t.Fatalf("ReadARAPlaybook: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	task := tasks[1]
+	if task.Role != "motd" || task.Module != "copy" || task.Play != "Provision web servers" ||
+		task.Path != "/srv/roles/motd/tasks/main.yml:4" || task.Duration != 1500*time.Millisecond {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	if len(task.Hosts) != 2 || task.Hosts[0].Status != "changed" || task.Hosts[1].Status != "fatal" ||
+		task.Hosts[0].ModuleArgs != `{"dest":"/etc/motd"}` || task.Hosts[1].Duration != 1400*time.Millisecond {
+		t.Errorf("unexpected host results: %+v", task.Hosts)
+	}
+	if !strings.Contains(task.RawText, "fatal: [web02]") {
+		t.Errorf("unexpected raw text: %q", task.RawText)
+	}
+	if _, err := ReadARAPlaybook(path, 3); err == nil {
+		t.Error("expected an error for a missing playbook")
+	}
+}
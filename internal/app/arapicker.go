@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ARAPicker is a screen listing the playbooks recorded in an ARA database,
+// shown before the task list so that one of them can be chosen to open.
+type ARAPicker struct {
+	playbooks []ARAPlaybook
+	selected  int
+	chosen    bool
+	width     int
+	viewport  viewport.Model
+}
+
+// NewARAPicker returns a picker for playbooks, most recent first as
+// ListARAPlaybooks returns them.
+func NewARAPicker(playbooks []ARAPlaybook) ARAPicker {
+	// Sized properly once the window size is known
+	p := ARAPicker{playbooks: playbooks, viewport: viewport.New(80, max(len(playbooks), 1))}
+	p.updateContent()
+	return p
+}
+
+// Chosen returns the ID of the playbook chosen with enter, or false if the
+// picker was left without choosing one.
+func (p ARAPicker) Chosen() (int64, bool) {
+	if !p.chosen {
+		return 0, false
+	}
+	return p.playbooks[p.selected].ID, true
+}
+
+func (p ARAPicker) Init() tea.Cmd {
+	return nil
+}
+
+func (p ARAPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		// Header, padding, column titles and help line
+		p.viewport.Width = max(p.width-4, 1)
+		p.viewport.Height = max(msg.Height-7, 1)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return p, tea.Quit
+		case "enter", " ":
+			if len(p.playbooks) > 0 {
+				p.chosen = true
+				return p, tea.Quit
+			}
+		case "down", "j":
+			p.selected = min(p.selected+1, len(p.playbooks)-1)
+		case "up", "k":
+			p.selected = max(p.selected-1, 0)
+		case "pgdown", "ctrl+d":
+			p.selected = min(p.selected+p.viewport.Height, len(p.playbooks)-1)
+		case "pgup", "ctrl+u":
+			p.selected = max(p.selected-p.viewport.Height, 0)
+		case "g", "home":
+			p.selected = 0
+		case "G", "end":
+			p.selected = len(p.playbooks) - 1
+		}
+	}
+	p.updateContent()
+	return p, nil
+}
+
+// updateContent renders the playbooks into the viewport, scrolled so that
+// the selected one is visible.
+func (p *ARAPicker) updateContent() {
+	var b strings.Builder
+	for i, pb := range p.playbooks {
+		line := araPlaybookLine(pb)
+		if i == p.selected {
+			line = selectedStyle.Width(max(p.width-4, 0)).Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	p.viewport.SetContent(b.String())
+	if p.selected < p.viewport.YOffset {
+		p.viewport.SetYOffset(p.selected)
+	} else if p.selected >= p.viewport.YOffset+p.viewport.Height {
+		p.viewport.SetYOffset(p.selected - p.viewport.Height + 1)
+	}
+}
+
+// araStatusWidth is the width of the status column of the playbook list.
+const araStatusWidth = len("COMPLETED")
+
+// araPlaybookLine formats a playbook as a line of the list, named by its
+// path or, if ARA recorded one, its name.
+func araPlaybookLine(pb ARAPlaybook) string {
+	name := pb.Path
+	if pb.Name != "" {
+		name = fmt.Sprintf("%s (%s)", pb.Name, pb.Path)
+	}
+	started := ""
+	if !pb.Started.IsZero() {
+		started = pb.Started.Format("2006-01-02 15:04:05")
+	}
+	status := statusStyleFor(araPlaybookStatus(pb.Status)).Render(strings.ToUpper(pb.Status)) +
+		strings.Repeat(" ", max(araStatusWidth-len(pb.Status), 0))
+	return fmt.Sprintf("%-6d %s %-19s %-10s %s", pb.ID, status, started, pb.Duration.Round(time.Second), name)
+}
+
+// araPlaybookStatus maps the status of an ARA playbook to the task status
+// it is coloured like.
+func araPlaybookStatus(status string) string {
+	switch status {
+	case "completed":
+		return "ok"
+	case "failed", "expired":
+		return "failed"
+	}
+	return status
+}
+
+func (p ARAPicker) View() string {
+	header := headerStyle.Width(p.width).Render("Ansible Logs TUI")
+	titles := dashboardSectionStyle.Render(fmt.Sprintf("%-6s %-*s %-19s %-10s %s",
+		"ID", araStatusWidth, "STATUS", "STARTED", "DURATION", "PLAYBOOK"))
+	help := helpStyle.Width(max(p.width-4, 0)).Render(
+		fmt.Sprintf("%d recorded playbooks • j/k, up/down: move • enter: open • q: quit", len(p.playbooks)))
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, titles, p.viewport.View(), help)),
+	)
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestARAPicker(t *testing.T) {
+	started := time.Date(2025, 10, 28, 14, 20, 31, 0, time.UTC)
+	playbooks := []ARAPlaybook{
+		{ID: 7, Path: "/srv/site.yml", Status: "completed", Started: started, Duration: 5 * time.Second},
+		{ID: 3, Name: "older", Path: "/srv/other.yml", Status: "failed", Started: started.Add(-24 * time.Hour)},
+		{ID: 1, Path: "/srv/first.yml", Status: "running"},
+	}
+	update := func(p ARAPicker, msgs ...tea.Msg) (ARAPicker, tea.Cmd) {
+		var model tea.Model = p
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			model, cmd = model.Update(msg)
+		}
+		return model.(ARAPicker), cmd
+	}
+	key := func(s string) tea.Msg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	p, _ := update(NewARAPicker(playbooks), tea.WindowSizeMsg{Width: 100, Height: 20})
+	view := stripANSI(p.View())
+	for _, want := range []string{"ID     STATUS    STARTED", "7      COMPLETED 2025-10-28 14:20:31 5s", "older (/srv/other.yml)", "3 recorded playbooks"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	p, cmd := update(p, key("j"), key("j"), key("j"), key("k"), tea.KeyMsg{Type: tea.KeyEnter})
+	if id, ok := p.Chosen(); !ok || id != 3 || cmd == nil {
+		t.Errorf("chosen %d, %v", id, ok)
+	}
+	p, cmd = update(NewARAPicker(playbooks), key("G"), key("q"))
+	if id, ok := p.Chosen(); ok || cmd == nil {
+		t.Errorf("quitting chose %d", id)
+	}
+}
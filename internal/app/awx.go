@@ -0,0 +1,184 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// awxEvent is a job event as returned by the AWX / Ansible Automation
+// Platform API (/api/v2/jobs/<id>/job_events/). Besides the runner event
+// fields it carries host, task, play and role at the top level.
+type awxEvent struct {
+	jobEvent
+	HostName string `json:"host_name"`
+	Task     string `json:"task"`
+	Play     string `json:"play"`
+	Role     string `json:"role"`
+}
+
+// awxPage is one page of a paged API response saved to disk.
+type awxPage struct {
+	Count   int        `json:"count"`
+	Results []awxEvent `json:"results"`
+}
+
+// IsAWXExport reports whether path is an AWX job event export: a JSON file
+// holding a page of job events or an array of them, or a directory whose
+// first JSON file does. Other JSON files aren't exports.
+func IsAWXExport(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		files, _ := filepath.Glob(filepath.Join(path, "*.json"))
+		if len(files) == 0 {
+			return false
+		}
+		sort.Strings(files)
+		path = files[0]
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return startsWithJobEvents(json.NewDecoder(f))
+}
+
+// startsWithJobEvents reports whether decoder reads a page object whose
+// "results" start with a job event, or an array starting with one. Only as
+// much is read as needed to tell.
+func startsWithJobEvents(decoder *json.Decoder) bool {
+	token, err := decoder.Token()
+	if err != nil {
+		return false
+	}
+	if token == json.Delim('[') {
+		return decoder.More() && isJobEvent(decoder)
+	}
+	if token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if key == "results" {
+			if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+				return false
+			}
+			return decoder.More() && isJobEvent(decoder)
+		}
+		var skipped json.RawMessage
+		if err := decoder.Decode(&skipped); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// isJobEvent reports whether the next value of decoder is an object with the
+// "event" or "event_data" fields of a job event.
+func isJobEvent(decoder *json.Decoder) bool {
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if key == "event" || key == "event_data" {
+			return true
+		}
+		var skipped json.RawMessage
+		if err := decoder.Decode(&skipped); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// ReadAWXExport reads AWX job events from a saved API response (a page
+// object with "results" or a plain array of events), or from a directory of
+// such pages, and turns them into tasks.
+func ReadAWXExport(path string) ([]Task, error) {
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	// Pages saved at different times may overlap, so events are deduplicated
+	seen := make(map[string]bool)
+	var events []jobEvent
+	for _, file := range files {
+		page, err := readAWXPage(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page {
+			key := event.UUID
+			if key == "" {
+				key = fmt.Sprint(event.Counter)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			events = append(events, event.normalize())
+		}
+	}
+	debugLog.Printf("ReadAWXExport() - Read %d events from %d files", len(events), len(files))
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no job events found in %s", path)
+	}
+	return buildTasksFromEvents(events), nil
+}
+
+// readAWXPage reads the events of one saved API response.
+func readAWXPage(file string) ([]awxEvent, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading export: %v", err)
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var events []awxEvent
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, fmt.Errorf("error parsing export %s: %v", file, err)
+		}
+		return events, nil
+	}
+	var page awxPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("error parsing export %s: %v", file, err)
+	}
+	return page.Results, nil
+}
+
+// normalize fills the event data from the top-level AWX fields, which are
+// the ones AWX documents and always sets.
+func (e awxEvent) normalize() jobEvent {
+	event := e.jobEvent
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&event.EventData.Host, e.HostName)
+	fill(&event.EventData.Task, e.Task)
+	fill(&event.EventData.Play, e.Play)
+	fill(&event.EventData.Role, e.Role)
+	return event
+}
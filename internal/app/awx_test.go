@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAWXExport(t *testing.T) {
+	dir := "../../testdata/awx-job-events"
+	if !IsAWXExport(dir) || !IsAWXExport(dir+"/page-1.json") || IsAWXExport("../../testdata/sample-demo.log") {
+		t.Fatal("IsAWXExport misdetects paths")
+	}
+	// Only the content tells exports from other JSON
+	other := t.TempDir()
+	for name, content := range map[string]string{
+		"events.json":   `[{"counter": 1, "event": "playbook_on_start", "event_data": {}}]`,
+		"page.json":     `{"count": 1, "next": null, "results": [{"event_data": {}, "event": "runner_on_ok"}]}`,
+		"config.json":   `{"rules": [{"pattern": "x"}], "results": 3}`,
+		"list.json":     `[{"name": "web01"}]`,
+		"events.log":    `[{"event": "runner_on_ok"}]`,
+		"broken.json":   `{"results": [`,
+		"ansible.log":   "TASK [Install] ***\nok: [web01]\n",
+		"empty.json":    `{"count": 0, "results": []}`,
+		"notjson.json":  "PLAY [all] ***",
+		"results.json":  `{"results": {"event": "x"}}`,
+		"nested.json":   `{"data": {"results": [{"event": "x"}]}}`,
+		"scalars.json":  `[1, 2]`,
+		"object.json":   `{}`,
+		"trailing.json": `[{"event_data": {"res": {}}}] trailing`,
+	} {
+		path := filepath.Join(other, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want := name == "events.json" || name == "page.json" || name == "events.log" || name == "trailing.json"
+		if got := IsAWXExport(path); got != want {
+			t.Errorf("IsAWXExport(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if IsAWXExport(other) {
+		t.Error("IsAWXExport took a directory of other JSON files for an export")
+	}
+	tasks, err := ReadAWXExport(dir)
+	if err != nil {
+		t.Fatalf("ReadAWXExport: %v", err)
+	}
+	runner, err := ReadRunnerArtifacts("../../testdata/runner-artifacts/1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != len(runner) {
+		t.Fatalf("got %d tasks, want %d", len(tasks), len(runner))
+	}
+	for i := range tasks {
+		got, want := tasks[i], runner[i]
+		if got.Description != want.Description || got.Status != want.Status || got.Play != "Provision web servers" ||
+			len(got.Hosts) != len(want.Hosts) || got.Duration != want.Duration {
+			t.Errorf("task %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if tasks[0].Hosts[1].Host != "web02" {
+		t.Errorf("host_name not mapped: %+v", tasks[0].Hosts)
+	}
+
+	// A single page holds the first task only partially
+	page, err := ReadAWXExport(dir + "/page-1.json")
+	if err != nil {
+		t.Fatalf("ReadAWXExport: %v", err)
+	}
+	if len(page) != 2 || len(page[0].Hosts) != 2 {
+		t.Errorf("unexpected tasks from one page: %+v", page)
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Config is the user configuration, read from a JSON file.
+type Config struct {
+	// Rules teach the parser about extra kinds of log lines
+	Rules []LineRule `json:"rules"`
+	// Redact holds extra regular expressions for secrets hidden in the
+	// details panel. If a pattern has a group named "secret", only that group
+	// is hidden.
+	Redact []string `json:"redact"`
+}
+
+// LineRule matches log lines with a regular expression and copies parts of
+// them into the current task. Values are templates expanded with the
+// regex's submatches ("$1", "${name}").
+//
+// A rule with neither Fields nor Attributes stores every named group of the
+// pattern as an attribute of the same name.
+type LineRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Fields sets task fields: "status", "host", "path" or "description".
+	// Setting both status and host records a host result.
+	Fields map[string]string `json:"fields"`
+	// Attributes sets custom task attributes, shown in the details panel and
+	// matched by the filter.
+	Attributes map[string]string `json:"attributes"`
+	// StatusMap translates the expanded status, e.g. {"REJECTED": "failed"}
+	StatusMap map[string]string `json:"status_map"`
+
+	regex *regexp.Regexp
+}
+
+// ruleFields are the task fields a LineRule may set.
+var ruleFields = map[string]bool{"status": true, "host": true, "path": true, "description": true}
+
+// DefaultConfigPath returns the default configuration file location,
+// $XDG_CONFIG_HOME/ansible-logs-view/config.json.
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ansible-logs-view", "config.json"), nil
+}
+
+// LoadConfig reads the configuration file at path. A missing file yields an
+// empty configuration unless required is set.
+func LoadConfig(path string, required bool) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %v", path, err)
+	}
+	for i := range cfg.Rules {
+		if err := cfg.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("error in config %s: rule %d: %v", path, i+1, err)
+		}
+	}
+	if _, err := NewRedactor(cfg.Redact); err != nil {
+		return nil, fmt.Errorf("error in config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+func (r *LineRule) compile() error {
+	regex, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	for field := range r.Fields {
+		if !ruleFields[field] {
+			return fmt.Errorf("unknown field %q", field)
+		}
+	}
+	r.regex = regex
+	return nil
+}
+
+// apply updates task if line matches the rule, reporting whether it did. If
+// the rule records a host result starting at the line last read by reader,
+// result is its index in task.Hosts, otherwise -1.
+func (r *LineRule) apply(task *Task, line string, reader *lineReader) (matched bool, result int) {
+	match := r.regex.FindStringSubmatchIndex(line)
+	if match == nil {
+		return false, -1
+	}
+	expand := func(template string) string {
+		return string(r.regex.ExpandString(nil, template, line, match))
+	}
+
+	if len(r.Fields) == 0 && len(r.Attributes) == 0 {
+		for i, name := range r.regex.SubexpNames() {
+			if name != "" && match[2*i] >= 0 {
+				task.setAttribute(name, line[match[2*i]:match[2*i+1]])
+			}
+		}
+	}
+	for name, template := range r.Attributes {
+		task.setAttribute(name, expand(template))
+	}
+
+	if template, ok := r.Fields["description"]; ok {
+		task.Description = expand(template)
+	}
+	if template, ok := r.Fields["path"]; ok {
+		task.Path = expand(template)
+	}
+	if template, ok := r.Fields["host"]; ok {
+		task.Host = expand(template)
+	}
+	if template, ok := r.Fields["status"]; ok {
+		status := expand(template)
+		if mapped, ok := r.StatusMap[status]; ok {
+			status = mapped
+		}
+		task.Status = status
+		if _, ok := r.Fields["host"]; ok {
+			return true, task.addHostResult(status, task.Host, reader)
+		}
+	}
+	return true, -1
+}
+
+// rulesFingerprint identifies a set of rules, so parse results cached with
+// different rules are not reused.
+func rulesFingerprint(rules []LineRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(rules)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	dashboardSectionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#25A065")).
+				Bold(true)
+
+	dashboardDimStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#888888"))
+)
+
+// dashboardSlowestTasks is the number of slowest tasks listed on the
+// dashboard.
+const dashboardSlowestTasks = 10
+
+// dashboardItem is a selectable line of the dashboard. Selecting it opens the
+// task list filtered by query and selects the task with taskID, if set.
+type dashboardItem struct {
+	section  string // Heading of the group of items the item is listed under
+	text     string
+	query    string
+	taskID   int
+	hostView bool // Open the host view, where statuses are those of each host
+}
+
+// dashboardStatuses are the statuses tasks are counted by on the dashboard,
+// matching the status: filter.
+var dashboardStatuses = []string{"ok", "changed", "failed", "unreachable", "skipping"}
+
+// buildDashboard summarises a run: its duration, task counts by status,
+// hosts with failures, slowest tasks, changed tasks per role and warnings.
+func buildDashboard(tasks []*Task) []dashboardItem {
+	var items []dashboardItem
+	add := func(section, text, query string, taskID int) {
+		items = append(items, dashboardItem{section: section, text: text, query: query, taskID: taskID})
+	}
+	count := func(query string) int {
+		compiled, err := parseFilterQuery(query, nil)
+		if err != nil {
+			return 0
+		}
+		n := 0
+		for _, task := range tasks {
+			if compiled.match(task) {
+				n++
+			}
+		}
+		return n
+	}
+
+	add("Run", fmt.Sprintf("%d tasks, %s", len(tasks), runDuration(tasks)), "", 0)
+
+	for _, status := range dashboardStatuses {
+		if n := count("status:" + status); n > 0 {
+			add("Tasks by status", fmt.Sprintf("%-12s %d", status, n), "status:"+status, 0)
+		}
+	}
+
+	// Hosts in the order they first failed
+	var failedHosts []string
+	failures := make(map[string]int)
+	for _, task := range tasks {
+		counted := make(map[string]bool)
+		for _, result := range task.Hosts {
+			host := inventoryHost(result.Host)
+			if !isFailure(result.Status) || counted[host] {
+				continue
+			}
+			counted[host] = true
+			if failures[host] == 0 {
+				failedHosts = append(failedHosts, host)
+			}
+			failures[host]++
+		}
+	}
+	// The host view lists the failed tasks of the host alone, the task list
+	// would also show tasks that failed on other hosts
+	for _, host := range failedHosts {
+		add("Hosts with failures", fmt.Sprintf("%-30s %d failed", host, failures[host]),
+			fmt.Sprintf("host:=%s (status:failed OR status:unreachable)", quoteQueryValue(host)), 0)
+		items[len(items)-1].hostView = true
+	}
+
+	slowest := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Duration > 0 {
+			slowest = append(slowest, task)
+		}
+	}
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	for _, task := range slowest[:min(len(slowest), dashboardSlowestTasks)] {
+		add("Slowest tasks", fmt.Sprintf("%10s  [%d] %s", task.Duration.Round(time.Millisecond), task.ID, task.Description), "", task.ID)
+	}
+
+	changed := make(map[string]int)
+	var roles []string
+	for _, task := range tasks {
+		if task.Role == "" || !task.hasHostStatus("changed") {
+			continue
+		}
+		if changed[task.Role] == 0 {
+			roles = append(roles, task.Role)
+		}
+		changed[task.Role]++
+	}
+	sort.SliceStable(roles, func(i, j int) bool { return changed[roles[i]] > changed[roles[j]] })
+	for _, role := range roles {
+		add("Changed tasks per role", fmt.Sprintf("%-30s %d", role, changed[role]),
+			fmt.Sprintf("role:=%s status:changed", quoteQueryValue(role)), 0)
+	}
+
+	warnings := 0
+	for _, task := range tasks {
+		warnings += len(task.Warnings)
+	}
+	add("Warnings", fmt.Sprintf("%d warnings in %d tasks", warnings, count("warning:*")), "warning:*", 0)
+	return items
+}
+
+// isFailure reports whether a host status is a failure.
+func isFailure(status string) bool {
+	return status == "failed" || status == "fatal" || status == "unreachable"
+}
+
+// quoteQueryValue quotes a filter query value containing spaces,
+// parentheses or quotes, escaping quotes and backslashes.
+func quoteQueryValue(value string) string {
+	if strings.ContainsAny(value, " \t()\"") {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
+
+// runDuration returns how long the run took, from the start of its first
+// task to the end of its last one, or the sum of the task durations if the
+// log has no timestamps.
+func runDuration(tasks []*Task) string {
+	var start, end time.Time
+	var total time.Duration
+	for _, task := range tasks {
+		total += task.Duration
+		if task.StartTime.IsZero() {
+			continue
+		}
+		if start.IsZero() || task.StartTime.Before(start) {
+			start = task.StartTime
+		}
+		if taskEnd := task.StartTime.Add(task.Duration); taskEnd.After(end) {
+			end = taskEnd
+		}
+	}
+	if !start.IsZero() && end.After(start) {
+		return "took " + end.Sub(start).Round(time.Second).String()
+	}
+	if total > 0 {
+		return "took " + total.Round(time.Second).String()
+	}
+	return "duration unknown"
+}
+
+// renderDashboard renders the dashboard with the selected item highlighted.
+func (m *Model) renderDashboard() string {
+	var b strings.Builder
+	section := ""
+	for i, item := range m.dashboardItems {
+		if item.section != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			section = item.section
+			b.WriteString(dashboardSectionStyle.Render(section) + "\n")
+		}
+		line := "  " + item.text
+		if i == m.dashboardSelected {
+			line = selectedStyle.Width(m.width - 4).Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + dashboardDimStyle.Render("enter: show in task list • esc: all tasks • D: back to this dashboard") + "\n")
+	return b.String()
+}
+
+// dashboardLine returns the line of the dashboard the selected item is on.
+func (m *Model) dashboardLine() int {
+	line, section := 0, ""
+	for i, item := range m.dashboardItems {
+		if item.section != section {
+			if section != "" {
+				line++
+			}
+			section = item.section
+			line++
+		}
+		if i == m.dashboardSelected {
+			return line
+		}
+		line++
+	}
+	return line
+}
+
+// updateDashboardContent renders the dashboard into its viewport, scrolled
+// so that the selected item is visible.
+func (m *Model) updateDashboardContent() {
+	m.dashboardViewport.SetContent(m.renderDashboard())
+	line := m.dashboardLine()
+	if line < m.dashboardViewport.YOffset {
+		m.dashboardViewport.SetYOffset(line)
+	} else if line >= m.dashboardViewport.YOffset+m.dashboardViewport.Height {
+		m.dashboardViewport.SetYOffset(line - m.dashboardViewport.Height + 1)
+	}
+}
+
+// openDashboardItem leaves the dashboard for the task list, or the host view
+// with the hosts expanded, filtered by the query of item. The filter can be
+// edited with "/" afterwards.
+func (m *Model) openDashboardItem(item dashboardItem) {
+	m.showDashboard = false
+	if m.hostView != item.hostView {
+		m.toggleHostView()
+	}
+	m.filterMode = filterModeQuery
+	m.filterInput.Prompt = m.filterMode.prompt()
+	m.filterInput.SetValue(item.query)
+	m.filterNodes(item.query)
+	if item.hostView {
+		for i := range m.filteredNodes {
+			m.filteredNodes[i].IsExpanded = true
+		}
+		m.rebuildFlatNodes()
+	}
+	m.updateViewports()
+	if item.taskID > 0 {
+		m.selectNode(item.taskID)
+	}
+	if item.query != "" {
+		m.statusMessage = "Filter: " + item.query
+	}
+}
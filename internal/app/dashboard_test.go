@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDashboard(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-demo.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	tasks[1].Warnings = []string{"first", "second"}
+
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	if !m.showDashboard {
+		t.Fatal("dashboard not shown on start")
+	}
+	sections := make(map[string]int)
+	var failed, slowest, warnings dashboardItem
+	for _, item := range m.dashboardItems {
+		sections[item.section]++
+		switch {
+		case item.query == "status:failed":
+			failed = item
+		case item.section == "Slowest tasks" && slowest.taskID == 0:
+			slowest = item
+		case item.section == "Warnings":
+			warnings = item
+		}
+	}
+	if sections["Run"] != 1 || sections["Hosts with failures"] != 2 || sections["Slowest tasks"] != 9 {
+		t.Errorf("dashboard sections = %v", sections)
+	}
+	if warnings.text != "2 warnings in 1 tasks" {
+		t.Errorf("warnings item = %q", warnings.text)
+	}
+	if slowest.taskID != 3 {
+		t.Errorf("slowest task = %d, want 3", slowest.taskID)
+	}
+
+	m.openDashboardItem(failed)
+	if m.showDashboard || len(m.flatNodes) != 1 || !m.flatNodes[0].node.Task.hasHostStatus("failed", "fatal") || m.filterInput.Value() != "status:failed" {
+		t.Errorf("failed item shows %d nodes with filter %q", len(m.flatNodes), m.filterInput.Value())
+	}
+	m.openDashboardItem(slowest)
+	if len(m.flatNodes) != len(tasks) || m.flatNodes[m.selected].node.ID != 3 {
+		t.Errorf("slowest item selects %d of %d nodes", m.flatNodes[m.selected].node.ID, len(m.flatNodes))
+	}
+	m.openDashboardItem(warnings)
+	if len(m.flatNodes) != 1 || m.flatNodes[0].node.ID != tasks[1].ID {
+		t.Errorf("warnings item shows %d nodes", len(m.flatNodes))
+	}
+}
+
+func TestDashboardHostQueries(t *testing.T) {
+	log := "TASK [Deploy] ***\n" +
+		`fatal: [web1]: FAILED! => {"changed": false, "msg": "boom"}` + "\n" +
+		`fatal: [web10]: FAILED! => {"changed": false, "msg": "boom"}` + "\n\n" +
+		"TASK [Restart] ***\n" +
+		`fatal: [web10]: FAILED! => {"changed": false, "msg": "boom"}` + "\n" +
+		"ok: [web1]\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	for _, item := range m.dashboardItems {
+		if item.section != "Hosts with failures" {
+			continue
+		}
+		m.openDashboardItem(item)
+		host, failed := strings.Fields(item.text)[0], 0
+		for _, fn := range m.flatNodes {
+			if fn.node.Task == nil && fn.node.Name != host {
+				t.Errorf("%q shows host %s", item.query, fn.node.Name)
+			}
+			if fn.node.Task != nil {
+				failed++
+			}
+		}
+		if want := map[string]int{"web1": 1, "web10": 2}[host]; failed != want || !strings.Contains(item.text, fmt.Sprintf(" %d failed", want)) {
+			t.Errorf("%q (%s) shows %d tasks, want %d", item.query, item.text, failed, want)
+		}
+	}
+	m.openDashboardItem(dashboardItem{})
+	if m.hostView || len(m.flatNodes) != 2 {
+		t.Errorf("leaving the dashboard shows %d nodes, host view %v", len(m.flatNodes), m.hostView)
+	}
+
+	for value, want := range map[string]string{
+		"web1":           "web1",
+		"my role":        `"my role"`,
+		`say "hi"`:       `"say \"hi\""`,
+		`back\slash "q"`: `"back\\slash \"q\""`,
+	} {
+		if got := quoteQueryValue(value); got != want {
+			t.Errorf("quoteQueryValue(%q) = %s, want %s", value, got, want)
+		}
+		tokens, err := tokenizeQuery("role:" + quoteQueryValue(value))
+		if err != nil || len(tokens) != 1 || tokens[0].text != "role:"+value {
+			t.Errorf("quoted %q reads back as %+v, %v", value, tokens, err)
+		}
+	}
+}
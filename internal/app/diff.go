@@ -0,0 +1,561 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// Header lines of the diffs printed by --diff, "--- before: /etc/motd"
+	// and "+++ after: /etc/motd"; the file is missing for some modules
+	diffBeforeRegex = regexp.MustCompile(`^--- before(?:: (.*))?$`)
+	diffAfterRegex  = regexp.MustCompile(`^\+\+\+ after(?:: (.*))?$`)
+
+	diffHeaderStyle     = lipgloss.NewStyle().Bold(true)
+	diffHunkStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#00AFAF"))
+	diffAddStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065"))
+	diffRemoveStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	diffAddWordStyle    = diffAddStyle.Background(lipgloss.Color("#1E4620")).Bold(true)
+	diffRemoveWordStyle = diffRemoveStyle.Background(lipgloss.Color("#5F1E1E")).Bold(true)
+)
+
+// maxLCSCells limits the size of the table computed by lcs.
+const maxLCSCells = 4_000_000
+
+// diffContextLines is the number of unchanged lines around changes in
+// generated diffs.
+const diffContextLines = 3
+
+// parseDiffSections splits diff text as printed by --diff into one section
+// per file.
+func parseDiffSections(diff string) []DiffSection {
+	var sections []DiffSection
+	var content []string
+	flush := func() {
+		if len(sections) > 0 {
+			sections[len(sections)-1].Content = strings.Join(content, "\n")
+		}
+		content = nil
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		if m := diffBeforeRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			sections = append(sections, DiffSection{BeforeFile: m[1]})
+			continue
+		}
+		if len(sections) == 0 {
+			sections = append(sections, DiffSection{})
+		}
+		if m := diffAfterRegex.FindStringSubmatch(line); m != nil && len(content) == 0 {
+			sections[len(sections)-1].AfterFile = m[1]
+			continue
+		}
+		content = append(content, line)
+	}
+	flush()
+	return sections
+}
+
+// diffHeaders returns the file header lines of section as Ansible prints
+// them.
+func diffHeaders(section DiffSection) (before, after string) {
+	before, after = "--- before", "+++ after"
+	if section.BeforeFile != "" {
+		before += ": " + section.BeforeFile
+	}
+	if section.AfterFile != "" {
+		after += ": " + section.AfterFile
+	}
+	return before, after
+}
+
+// renderDiff renders diff text with styled file headers, hunk headers and
+// added and removed lines. In a removed line followed by an added one, the
+// words that changed are highlighted.
+func renderDiff(diff string) string {
+	var b strings.Builder
+	for _, section := range parseDiffSections(diff) {
+		if section.BeforeFile != "" || section.AfterFile != "" {
+			before, after := diffHeaders(section)
+			b.WriteString(diffHeaderStyle.Render(before) + "\n")
+			b.WriteString(diffHeaderStyle.Render(after) + "\n")
+		}
+		lines := strings.Split(section.Content, "\n")
+		for i := 0; i < len(lines); {
+			line := lines[i]
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				b.WriteString(diffHunkStyle.Render(line) + "\n")
+				i++
+			case strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+"):
+				// Lines of a run of removed lines followed by added lines
+				// are paired up and compared word by word
+				j := i
+				for j < len(lines) && strings.HasPrefix(lines[j], "-") {
+					j++
+				}
+				k := j
+				for k < len(lines) && strings.HasPrefix(lines[k], "+") {
+					k++
+				}
+				removed, added := lines[i:j], lines[j:k]
+				pairs := min(len(removed), len(added))
+				for n, line := range removed {
+					if n < pairs {
+						removedText, _ := wordDiff(line[1:], added[n][1:])
+						b.WriteString(diffRemoveStyle.Render("-") + removedText + "\n")
+					} else {
+						b.WriteString(diffRemoveStyle.Render(line) + "\n")
+					}
+				}
+				for n, line := range added {
+					if n < pairs {
+						_, addedText := wordDiff(removed[n][1:], line[1:])
+						b.WriteString(diffAddStyle.Render("+") + addedText + "\n")
+					} else {
+						b.WriteString(diffAddStyle.Render(line) + "\n")
+					}
+				}
+				i = k
+			default:
+				b.WriteString(line + "\n")
+				i++
+			}
+		}
+	}
+	return b.String()
+}
+
+// wordDiff renders a removed and an added line, highlighting the words of
+// each that are not in the other.
+func wordDiff(removed, added string) (string, string) {
+	a, b := splitWords(removed), splitWords(added)
+	common := lcs(a, b)
+	inA := make([]bool, len(a))
+	inB := make([]bool, len(b))
+	for _, pair := range common {
+		inA[pair[0]] = true
+		inB[pair[1]] = true
+	}
+	return renderWords(a, inA, diffRemoveStyle, diffRemoveWordStyle), renderWords(b, inB, diffAddStyle, diffAddWordStyle)
+}
+
+// renderWords renders runs of unchanged words with style and of changed
+// words with changedStyle.
+func renderWords(words []string, unchanged []bool, style, changedStyle lipgloss.Style) string {
+	var b strings.Builder
+	for i := 0; i < len(words); {
+		j := i
+		for j < len(words) && unchanged[j] == unchanged[i] {
+			j++
+		}
+		run := strings.Join(words[i:j], "")
+		if unchanged[i] {
+			b.WriteString(style.Render(run))
+		} else {
+			b.WriteString(changedStyle.Render(run))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// splitWords splits s into words, runs of spaces and single other
+// characters, which joined give s again.
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 0
+		case unicode.IsSpace(r):
+			return 1
+		}
+		return 2
+	}
+	for i := 0; i < len(runes); {
+		j := i + 1
+		if c := class(runes[i]); c != 2 {
+			for j < len(runes) && class(runes[j]) == c {
+				j++
+			}
+		}
+		words = append(words, string(runes[i:j]))
+		i = j
+	}
+	return words
+}
+
+// lcs returns the index pairs of a longest common subsequence of a and b, or
+// nil if they are too long to compare.
+func lcs(a, b []string) [][2]int {
+	if len(a)*len(b) > maxLCSCells {
+		return nil
+	}
+	// length[i][j] is the LCS length of a[i:] and b[j:]
+	length := make([][]int, len(a)+1)
+	for i := range length {
+		length[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				length[i][j] = length[i+1][j+1] + 1
+			} else {
+				length[i][j] = max(length[i+1][j], length[i][j+1])
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case length[i+1][j] >= length[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// unifiedDiff returns a unified diff between the texts before and after,
+// with file headers as printed by --diff, or "" if they are equal.
+func unifiedDiff(beforeFile, afterFile, before, after string) string {
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	a, b := split(before), split(after)
+
+	// Edit script: ' ', '-' or '+' followed by the line
+	var ops []string
+	i, j := 0, 0
+	for _, pair := range append(lcs(a, b), [2]int{len(a), len(b)}) {
+		for ; i < pair[0]; i++ {
+			ops = append(ops, "-"+a[i])
+		}
+		for ; j < pair[1]; j++ {
+			ops = append(ops, "+"+b[j])
+		}
+		if i < len(a) && j < len(b) {
+			ops = append(ops, " "+a[i])
+			i++
+			j++
+		}
+	}
+
+	var hunks strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close
+		first := start
+		for first < len(ops) && ops[first][0] == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for k := first; k < len(ops) && k <= last+2*diffContextLines; k++ {
+			if ops[k][0] != ' ' {
+				last = k
+			}
+		}
+		from := max(first-diffContextLines, start)
+		to := min(last+diffContextLines+1, len(ops))
+
+		// Line numbers of the hunk in both texts
+		aLine, bLine := 1, 1
+		for _, op := range ops[:from] {
+			if op[0] != '+' {
+				aLine++
+			}
+			if op[0] != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op[0] != '+' {
+				aCount++
+			}
+			if op[0] != '-' {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aLine--
+		}
+		if bCount == 0 {
+			bLine--
+		}
+		fmt.Fprintf(&hunks, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, op := range ops[from:to] {
+			hunks.WriteString(op + "\n")
+		}
+		start = to
+	}
+	if hunks.Len() == 0 {
+		return ""
+	}
+	beforeHeader, afterHeader := diffHeaders(DiffSection{BeforeFile: beforeFile, AfterFile: afterFile})
+	return beforeHeader + "\n" + afterHeader + "\n" + strings.TrimSuffix(hunks.String(), "\n")
+}
+
+// resultDiff returns the diff in the "diff" return value of a module, an
+// object or list of objects holding either the texts before and after the
+// change or a prepared diff, as unified diff text.
+func resultDiff(res map[string]any) string {
+	var entries []any
+	switch diff := res["diff"].(type) {
+	case map[string]any:
+		entries = []any{diff}
+	case []any:
+		entries = diff
+	}
+	// Values that aren't text, such as a file's state, are compared as JSON
+	text := func(value any) string {
+		if s, ok := value.(string); ok {
+			return s
+		}
+		if value == nil {
+			return ""
+		}
+		data, _ := json.MarshalIndent(value, "", "    ")
+		return string(data) + "\n"
+	}
+	var diffs []string
+	for _, entry := range entries {
+		diff, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		if prepared, ok := diff["prepared"].(string); ok && prepared != "" {
+			diffs = append(diffs, strings.TrimSuffix(prepared, "\n"))
+			continue
+		}
+		beforeFile, _ := diff["before_header"].(string)
+		afterFile, _ := diff["after_header"].(string)
+		if d := unifiedDiff(beforeFile, afterFile, text(diff["before"]), text(diff["after"])); d != "" {
+			diffs = append(diffs, d)
+		}
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// colorizeDiffs styles the diffs within raw task output, from a
+// "--- before" header up to the next blank or status line. The lines may
+// already contain escape sequences.
+func colorizeDiffs(text string) string {
+	lines := strings.Split(text, "\n")
+	inDiff := false
+	for i, line := range lines {
+		plain := stripANSI(line)
+		switch {
+		case diffBeforeRegex.MatchString(plain):
+			inDiff = true
+			lines[i] = diffHeaderStyle.Render(line)
+		case !inDiff:
+		case plain == "" || statusLineRegex.MatchString(plain):
+			inDiff = false
+		case diffAfterRegex.MatchString(plain):
+			lines[i] = diffHeaderStyle.Render(line)
+		case strings.HasPrefix(plain, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		case strings.HasPrefix(plain, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(plain, "-"):
+			lines[i] = diffRemoveStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffHunkRegex matches a hunk header and captures the first line numbers
+// of the hunk before and after the change.
+var diffHunkRegex = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// diffTabWidth is the number of spaces tabs are expanded to in side-by-side
+// diffs, so that the columns stay aligned.
+const diffTabWidth = 4
+
+// diffSegment is a piece of a diff line rendered with one style.
+type diffSegment struct {
+	text  string
+	style lipgloss.Style
+}
+
+// diffCell is one side of a row of a side-by-side diff.
+type diffCell struct {
+	line     int // Line number, 0 for an empty cell
+	segments []diffSegment
+}
+
+// renderSideBySide renders diff text with the text before the change on the
+// left and after it on the right, aligning unchanged lines and pairing
+// removed with added lines. Each row is width columns wide, and lines are
+// shown from column offset on.
+func renderSideBySide(diff string, width, offset int) string {
+	const separator = " │ "
+	cellWidth := max((width-len([]rune(separator)))/2, 8)
+	var b strings.Builder
+	for _, section := range parseDiffSections(diff) {
+		before, after := diffHeaders(section)
+		b.WriteString(diffHeaderStyle.Render(padCell(before, cellWidth)) + separator +
+			diffHeaderStyle.Render(padCell(after, cellWidth)) + "\n")
+
+		var left, right []diffCell
+		// Pads the shorter column so that the next rows line up again
+		align := func() {
+			for len(left) < len(right) {
+				left = append(left, diffCell{})
+			}
+			for len(right) < len(left) {
+				right = append(right, diffCell{})
+			}
+		}
+		flush := func() {
+			align()
+			for i := range left {
+				b.WriteString(renderDiffCell(left[i], cellWidth, offset) + separator +
+					renderDiffCell(right[i], cellWidth, offset) + "\n")
+			}
+			left, right = nil, nil
+		}
+
+		lines := strings.Split(section.Content, "\n")
+		leftLine, rightLine := 1, 1
+		for i := 0; i < len(lines); {
+			line := strings.ReplaceAll(lines[i], "\t", strings.Repeat(" ", diffTabWidth))
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				flush()
+				if m := diffHunkRegex.FindStringSubmatch(line); m != nil {
+					leftLine, _ = strconv.Atoi(m[1])
+					rightLine, _ = strconv.Atoi(m[2])
+				}
+				b.WriteString(diffHunkStyle.Render(line) + "\n")
+				i++
+			case strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+"):
+				j := i
+				for j < len(lines) && strings.HasPrefix(lines[j], "-") {
+					j++
+				}
+				k := j
+				for k < len(lines) && strings.HasPrefix(lines[k], "+") {
+					k++
+				}
+				expand := func(lines []string) []string {
+					expanded := make([]string, len(lines))
+					for n, line := range lines {
+						expanded[n] = strings.ReplaceAll(line[1:], "\t", strings.Repeat(" ", diffTabWidth))
+					}
+					return expanded
+				}
+				removed, added := expand(lines[i:j]), expand(lines[j:k])
+				for n, text := range removed {
+					segments := []diffSegment{{text, diffRemoveStyle}}
+					if n < len(added) {
+						segments = wordSegments(text, added[n], diffRemoveStyle, diffRemoveWordStyle)
+					}
+					left = append(left, diffCell{leftLine, segments})
+					leftLine++
+				}
+				for n, text := range added {
+					segments := []diffSegment{{text, diffAddStyle}}
+					if n < len(removed) {
+						segments = wordSegments(text, removed[n], diffAddStyle, diffAddWordStyle)
+					}
+					right = append(right, diffCell{rightLine, segments})
+					rightLine++
+				}
+				align()
+				i = k
+			default:
+				if line == "" && i == len(lines)-1 {
+					i++
+					continue
+				}
+				text := strings.TrimPrefix(line, " ")
+				left = append(left, diffCell{leftLine, []diffSegment{{text, lipgloss.NewStyle()}}})
+				right = append(right, diffCell{rightLine, []diffSegment{{text, lipgloss.NewStyle()}}})
+				leftLine++
+				rightLine++
+				i++
+			}
+		}
+		flush()
+	}
+	return b.String()
+}
+
+// wordSegments splits line into segments styled with style where its words
+// are also in other and with changedStyle where they are not.
+func wordSegments(line, other string, style, changedStyle lipgloss.Style) []diffSegment {
+	words, otherWords := splitWords(line), splitWords(other)
+	unchanged := make([]bool, len(words))
+	for _, pair := range lcs(words, otherWords) {
+		unchanged[pair[0]] = true
+	}
+	var segments []diffSegment
+	for i, word := range words {
+		s := changedStyle
+		if unchanged[i] {
+			s = style
+		}
+		segments = append(segments, diffSegment{word, s})
+	}
+	return segments
+}
+
+// renderDiffCell renders a cell of a side-by-side diff exactly width columns
+// wide: its line number followed by its text from column offset on.
+func renderDiffCell(cell diffCell, width, offset int) string {
+	if cell.line == 0 {
+		return strings.Repeat(" ", width)
+	}
+	number := fmt.Sprintf("%4d ", cell.line)
+	textWidth := max(width-len(number), 0)
+	var b strings.Builder
+	used := 0
+	skip := offset
+	for _, segment := range cell.segments {
+		runes := []rune(segment.text)
+		if skip >= len(runes) {
+			skip -= len(runes)
+			continue
+		}
+		runes = runes[skip:]
+		skip = 0
+		if len(runes) > textWidth-used {
+			runes = runes[:textWidth-used]
+		}
+		b.WriteString(segment.style.Render(string(runes)))
+		used += len(runes)
+		if used == textWidth {
+			break
+		}
+	}
+	return diffHunkStyle.Render(number) + b.String() + strings.Repeat(" ", textWidth-used)
+}
+
+// padCell cuts or pads s to exactly width columns.
+func padCell(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFileHostDiffs(t *testing.T) {
+	log := `TASK [Configure motd] ***
+--- before: /etc/motd
++++ after: /home/user/.ansible/tmp/motd.j2
+@@ -1,2 +1,2 @@
+ Welcome
+-Managed by hand
++Managed by Ansible
+
+changed: [web01]
+--- before: /etc/motd
++++ after: /home/user/.ansible/tmp/motd.j2
+@@ -1 +1,2 @@
+ Welcome
++Managed by Ansible
+changed: [web02]
+ok: [web03]
+
+TASK [Next] ***
+ok: [web01]
+`
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	hosts := tasks[0].Hosts
+	if len(hosts) != 3 {
+		t.Fatalf("got %d host results, want 3: %+v", len(hosts), hosts)
+	}
+	if !strings.Contains(hosts[0].Diff, "-Managed by hand") || !strings.HasSuffix(hosts[1].Diff, "+Managed by Ansible") ||
+		strings.Contains(hosts[1].Diff, "by hand") || hosts[2].Diff != "" {
+		t.Errorf("unexpected host diffs: %+v", hosts)
+	}
+	if hosts[1].Status != "changed" || len(tasks[1].Hosts) != 1 || tasks[1].Diff != "" {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+
+	sections := parseDiffSections(hosts[0].Diff)
+	if len(sections) != 1 || sections[0].BeforeFile != "/etc/motd" || sections[0].AfterFile != "/home/user/.ansible/tmp/motd.j2" ||
+		!strings.HasPrefix(sections[0].Content, "@@ -1,2 +1,2 @@") {
+		t.Errorf("unexpected sections: %+v", sections)
+	}
+	rendered := renderDiffTab(&tasks[0], func(s string) string { return s }, renderDiff)
+	if !strings.Contains(rendered, "web01 (changed):\n--- before: /etc/motd") || !strings.Contains(rendered, "-Managed by hand") {
+		t.Errorf("unexpected diff tab:\n%s", rendered)
+	}
+}
+
+func TestDiffHelpers(t *testing.T) {
+	removed, added := wordDiff("listen 80;", "listen 443 ssl;")
+	if stripANSI(removed) != "listen 80;" || stripANSI(added) != "listen 443 ssl;" {
+		t.Errorf("wordDiff changed the text: %q, %q", removed, added)
+	}
+	if got := strings.Join(splitWords("listen  443;"), "|"); got != "listen|  |443|;" {
+		t.Errorf("splitWords = %q", got)
+	}
+
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	want := `--- before: /etc/x
++++ after: /etc/x
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k`
+	if got := unifiedDiff("/etc/x", "/etc/x", before, after); got != want {
+		t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := unifiedDiff("", "", "same\n", "same\n"); got != "" {
+		t.Errorf("unifiedDiff of equal texts = %q", got)
+	}
+
+	res := map[string]any{"diff": []any{
+		map[string]any{"before": map[string]any{"state": "absent"}, "after": map[string]any{"state": "directory"}},
+		map[string]any{"prepared": "--- before\n+++ after\n@@ -1 +1 @@\n-x\n+y\n"},
+	}}
+	diff := resultDiff(res)
+	if !strings.Contains(diff, `-    "state": "absent"`) || !strings.Contains(diff, `+    "state": "directory"`) ||
+		!strings.HasSuffix(diff, "-x\n+y") {
+		t.Errorf("resultDiff =\n%s", diff)
+	}
+}
+
+func TestRenderSideBySide(t *testing.T) {
+	diff := "--- before: /etc/app.conf\n+++ after: /etc/app.conf\n@@ -10,4 +10,3 @@\n" +
+		" # managed\n-port = 80\n+port = 8080\n host = example.org\n-debug = true\n end"
+	rendered := stripANSI(renderSideBySide(diff, 41, 0))
+	want := []string{
+		"--- before: /etc/ap │ +++ after: /etc/app",
+		"@@ -10,4 +10,3 @@",
+		"  10 # managed      │   10 # managed     ",
+		"  11 port = 80      │   11 port = 8080   ",
+		"  12 host = example │   12 host = example",
+		"  13 debug = true   │                    ",
+		"  14 end            │   13 end           ",
+	}
+	lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("renderSideBySide =\n%s", rendered)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+
+	// Scrolled to the right, the line numbers stay
+	scrolled := stripANSI(renderSideBySide(diff, 41, 5))
+	if !strings.Contains(scrolled, "  11 = 80 ") || !strings.Contains(scrolled, "  11 = 8080 ") {
+		t.Errorf("scrolled renderSideBySide =\n%s", scrolled)
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportTasks writes the raw text of tasks to w as the log shows them,
+// without colour codes. Secrets are always hidden with redactor, whether or
+// not they are shown in the TUI, so that exports can be shared.
+func ExportTasks(w io.Writer, tasks []Task, redactor *Redactor) error {
+	for i := range tasks {
+		text, err := tasks[i].LoadRawText()
+		if err != nil {
+			return fmt.Errorf("error reading task %d: %v", tasks[i].ID, err)
+		}
+		text = redactor.Redact(stripANSI(text))
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			return fmt.Errorf("error writing export: %v", err)
+		}
+	}
+	return nil
+}
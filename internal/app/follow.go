@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// followInterval is how often the log is checked for new output in follow
+// mode.
+const followInterval = time.Second
+
+// followTickMsg is sent every followInterval in follow mode.
+type followTickMsg struct{}
+
+func followTick() tea.Cmd {
+	return tea.Tick(followInterval, func(time.Time) tea.Msg { return followTickMsg{} })
+}
+
+// followMsg carries what the follow function of the model returned.
+type followMsg struct {
+	tasks   []Task
+	changed bool
+	err     error
+}
+
+// SetFollow turns on follow mode: the task list is updated with the tasks
+// follow returns whenever it reports that they changed, like LogParser.Follow.
+func (m *Model) SetFollow(follow func() ([]Task, bool, error)) {
+	m.follow = follow
+}
+
+// readFollow reads new output of the log outside of the update loop, as
+// parsing it may take a while.
+func (m *Model) readFollow() tea.Cmd {
+	follow := m.follow
+	return func() tea.Msg {
+		tasks, changed, err := follow()
+		return followMsg{tasks: tasks, changed: changed, err: err}
+	}
+}
+
+// updateFollow shows the tasks read by readFollow.
+func (m *Model) updateFollow(msg followMsg) {
+	if msg.err != nil {
+		debugLog.Printf("updateFollow() - Error following log: %v", msg.err)
+		m.statusMessage = fmt.Sprintf("Follow failed: %v", msg.err)
+		return
+	}
+	if msg.changed {
+		m.setTasks(msg.tasks)
+	}
+}
+
+// setTasks replaces the tasks shown, keeping the view, filter, expanded
+// nodes and selection. When the last node was selected, the new last node is
+// selected instead, so that the list keeps up with the log.
+func (m *Model) setTasks(tasks []Task) {
+	atEnd := m.selected >= len(m.flatNodes)-1
+	var selected nodeKey
+	if len(m.flatNodes) > 0 {
+		selected = m.nodeKeyOf(m.flatNodes[m.selected].node)
+	}
+	expanded := make(map[int]bool)
+	for _, node := range m.filteredNodes {
+		expanded[node.ID] = node.IsExpanded
+	}
+
+	taskPointers := make([]*Task, len(tasks))
+	for i := range tasks {
+		taskPointers[i] = &tasks[i]
+	}
+	m.taskNodes = convertTasksToNodes(tasks)
+	m.hostNodes = nil
+	m.nodes = m.taskNodes
+	if m.hostView {
+		m.hostNodes = convertTasksToHostNodes(taskPointers)
+		m.nodes = m.hostNodes
+	}
+	for i := range m.nodes {
+		m.nodes[i].IsExpanded = expanded[m.nodes[i].ID]
+	}
+	m.dashboardItems = buildDashboard(taskPointers)
+	m.attributeNames = attributeNames(taskPointers)
+	m.dashboardSelected = min(m.dashboardSelected, max(len(m.dashboardItems)-1, 0))
+	// The last task may have grown, so its cached text and result are stale
+	m.rawTextNodeID = 0
+	m.resultTree = nil
+
+	m.filterNodes(m.filterInput.Value())
+	// Only tasks searched before have hits, under the new nodes
+	m.remapSearchHits()
+	m.updateViewports()
+	if atEnd || !m.selectNodeKey(selected) {
+		m.selectIndex(max(len(m.flatNodes)-1, 0))
+	}
+	debugLog.Printf("setTasks() - Showing %d tasks", len(tasks))
+}
+
+// nodeKey identifies a node of the node list by its task and host rather
+// than by its ID, since the tasks of hosts in the host view are numbered
+// anew whenever tasks are added.
+type nodeKey struct {
+	taskID int    // Zero for the hosts of the host view
+	host   string // Empty for the tasks of the task list
+}
+
+func (m *Model) nodeKeyOf(node *TreeNode) nodeKey {
+	switch {
+	case node.Task == nil:
+		return nodeKey{host: node.Host}
+	case m.hostView:
+		return nodeKey{taskID: node.Task.ID, host: node.Host}
+	}
+	return nodeKey{taskID: node.Task.ID}
+}
+
+// selectNodeKey selects the visible node identified by key and reports
+// whether it was found.
+func (m *Model) selectNodeKey(key nodeKey) bool {
+	for i, fn := range m.flatNodes {
+		if m.nodeKeyOf(fn.node) == key {
+			m.selectIndex(i)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFollow(t *testing.T) {
+	path := writeLog(t, "TASK [Install] ***\nchanged: [web1]\n\nTASK [Configure] ***\n")
+	parser := NewLogParser(false)
+	defer parser.Close()
+	parser.SetIndexCacheDir(t.TempDir())
+	tasks, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.SetFollow(parser.Follow)
+	m.selectIndex(1)
+	follow := func() { m.updateFollow(m.readFollow()().(followMsg)) }
+
+	follow()
+	if len(m.flatNodes) != 2 || m.statusMessage != "" {
+		t.Fatalf("unchanged log shows %d tasks, status %q", len(m.flatNodes), m.statusMessage)
+	}
+
+	// The last task is parsed again with the lines appended to it
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("ok: [web1]\n\nTASK [Restart] ***\nchanged: [web1]\n")
+	file.Close()
+	follow()
+	if len(m.flatNodes) != 3 || m.flatNodes[1].node.Status != "ok" || m.flatNodes[2].node.Task.ID != 3 {
+		t.Fatalf("followed log shows %+v", m.flatNodes)
+	}
+	if m.selected != 2 {
+		t.Errorf("selected %d, want the new last task", m.selected)
+	}
+	if text, _ := m.flatNodes[1].node.Task.LoadRawText(); text != "TASK [Configure] ***\nok: [web1]\n\n" {
+		t.Errorf("raw text of the grown task = %q", text)
+	}
+
+	// The index isn't saved on every read, but when the parser is closed
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexed := func() int {
+		idx := loadParseIndex(parser.cacheDir, absPath)
+		if idx == nil {
+			t.Fatal("no index saved")
+		}
+		return len(idx.Tasks)
+	}
+	if n := indexed(); n != 2 {
+		t.Errorf("index of %d tasks saved while following", n)
+	}
+	if err := parser.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if parser.unsaved != nil || indexed() != 3 {
+		t.Errorf("index not saved on close")
+	}
+	if _, err := parser.ParseFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A truncated log is parsed again from the start
+	if err := os.WriteFile(path, []byte("TASK [Other] ***\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	follow()
+	if len(m.flatNodes) != 1 || m.flatNodes[0].node.Name != "Other" {
+		t.Errorf("truncated log shows %d tasks", len(m.flatNodes))
+	}
+}
+
+func TestFollowHostView(t *testing.T) {
+	path := writeLog(t, "TASK [A] ***\nok: [h1]\nok: [h2]\n\nTASK [B] ***\nok: [h1]\nok: [h2]\n\nTASK [C] ***\nok: [h1]\n")
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.SetFollow(parser.Follow)
+	m.toggleHostView()
+	m.hostNodes[0].IsExpanded = true
+	m.filterNodes("")
+	m.selectIndex(2)
+	if node := m.flatNodes[m.selected].node; node.Name != "B" || node.Host != "h1" {
+		t.Fatalf("selected %q on %q", node.Name, node.Host)
+	}
+
+	// New tasks number the tasks of hosts anew
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("\nTASK [D] ***\nok: [h1]\nok: [h2]\n")
+	file.Close()
+	m.updateFollow(m.readFollow()().(followMsg))
+	if node := m.flatNodes[m.selected].node; node.Name != "B" || node.Host != "h1" {
+		t.Errorf("selected %q on %q after following, want B on h1", node.Name, node.Host)
+	}
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hostRecap counts the tasks of a host by outcome, like the PLAY RECAP line
+// Ansible prints for it. Ok includes changed tasks, as in the recap.
+type hostRecap struct {
+	Ok, Changed, Unreachable, Failed, Skipped int
+}
+
+func (r hostRecap) String() string {
+	return fmt.Sprintf("ok=%d changed=%d unreachable=%d failed=%d skipped=%d",
+		r.Ok, r.Changed, r.Unreachable, r.Failed, r.Skipped)
+}
+
+// add counts a task that ended with status on the host.
+func (r *hostRecap) add(status string) {
+	switch status {
+	case "ok":
+		r.Ok++
+	case "changed":
+		r.Ok++
+		r.Changed++
+	case "unreachable":
+		r.Unreachable++
+	case "failed", "fatal":
+		r.Failed++
+	case "skipping":
+		r.Skipped++
+	}
+}
+
+// status returns the overall status of the host: the worst outcome of its
+// tasks.
+func (r hostRecap) status() string {
+	switch {
+	case r.Unreachable > 0:
+		return "unreachable"
+	case r.Failed > 0:
+		return "failed"
+	case r.Changed > 0:
+		return "changed"
+	case r.Ok > 0:
+		return "ok"
+	case r.Skipped > 0:
+		return "skipping"
+	}
+	return "unknown"
+}
+
+// hostStatusRank orders statuses from least to most severe, to pick the
+// status of a task on a host that reported several results (loop items).
+var hostStatusRank = map[string]int{"skipping": 1, "ok": 2, "changed": 3, "failed": 4, "fatal": 5, "unreachable": 6}
+
+// convertTasksToHostNodes builds the nodes of the host view: one node per
+// host, in the order the hosts first appear, with a child node for every task
+// that ran on the host, in the order they ran. The task of a child node is a
+// copy of the task with only the results of that host, so that filters and
+// the details panel look at the host alone. Every host lists the task under
+// its own node ID, numbered on from the last task ID, so that selecting a
+// node finds the right host; the task ID stays in the Task of the node.
+func convertTasksToHostNodes(tasks []*Task) []TreeNode {
+	var nodes []TreeNode
+	hostIndex := make(map[string]int) // Host name -> index in nodes
+	nextID := 0
+	for _, task := range tasks {
+		nextID = max(nextID, task.ID)
+	}
+	for _, task := range tasks {
+		// A task reports one result per host, or one per loop item
+		var hosts []string
+		results := make(map[string][]HostResult)
+		for _, result := range task.Hosts {
+			host := inventoryHost(result.Host)
+			if results[host] == nil {
+				hosts = append(hosts, host)
+			}
+			results[host] = append(results[host], result)
+		}
+		for _, host := range hosts {
+			hostTask := *task
+			hostTask.Hosts = results[host]
+			hostTask.Host = host
+			hostTask.Status = ""
+			hostTask.Diff = ""
+			for _, result := range hostTask.Hosts {
+				if hostStatusRank[result.Status] > hostStatusRank[hostTask.Status] {
+					hostTask.Status = result.Status
+				}
+				if result.Diff != "" {
+					hostTask.Diff = strings.TrimPrefix(hostTask.Diff+"\n"+result.Diff, "\n")
+				}
+			}
+			// Job events and ARA time each host
+			if start := hostTask.Hosts[0].StartTime; !start.IsZero() {
+				hostTask.StartTime = start
+				hostTask.Duration = 0
+				for _, result := range hostTask.Hosts {
+					hostTask.Duration += result.Duration
+				}
+			}
+
+			i, ok := hostIndex[host]
+			if !ok {
+				i = len(nodes)
+				hostIndex[host] = i
+				nodes = append(nodes, TreeNode{
+					// Negative IDs keep host nodes apart from the tasks
+					ID:   -(i + 1),
+					Name: host,
+					Host: host,
+				})
+			}
+			nextID++
+			nodes[i].Children = append(nodes[i].Children, TreeNode{
+				ID:        nextID,
+				Name:      task.Description,
+				StartTime: hostTask.StartTime,
+				Status:    hostTask.Status,
+				Host:      host,
+				Path:      task.Path,
+				Diff:      hostTask.Diff,
+				Task:      &hostTask,
+			})
+		}
+	}
+	for i := range nodes {
+		var recap hostRecap
+		for _, child := range nodes[i].Children {
+			recap.add(child.Status)
+		}
+		nodes[i].Status = recap.status()
+		nodes[i].Recap = recap.String()
+	}
+	return nodes
+}
+
+// inventoryHost returns the host a result was reported for without the host
+// the task was delegated to, e.g. "web01" for "web01 -> localhost".
+func inventoryHost(host string) string {
+	name, _, _ := strings.Cut(host, " -> ")
+	return name
+}
+
+// toggleHostView switches the node list between tasks and hosts, keeping
+// the filter.
+func (m *Model) toggleHostView() {
+	m.hostView = !m.hostView
+	if m.hostView {
+		if m.hostNodes == nil {
+			tasks := make([]*Task, 0, len(m.taskNodes))
+			for _, node := range m.taskNodes {
+				tasks = append(tasks, node.Task)
+			}
+			m.hostNodes = convertTasksToHostNodes(tasks)
+			debugLog.Printf("toggleHostView() - Built %d host nodes", len(m.hostNodes))
+		}
+		m.nodes = m.hostNodes
+		m.statusMessage = "Host view"
+	} else {
+		m.nodes = m.taskNodes
+		m.statusMessage = "Task view"
+	}
+	m.selected = 0
+	m.filterNodes(m.filterInput.Value())
+	m.remapSearchHits()
+	m.updateViewports()
+}
+
+// renderHostSummary renders the recap of the host of node and the tasks that
+// ran on it, for the details panel of a host in the host view.
+func renderHostSummary(node *TreeNode) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Host: %s\nRecap: %s\n\n", node.Name, node.Recap)
+	for _, child := range node.Children {
+		started := "        "
+		if !child.StartTime.IsZero() {
+			started = child.StartTime.Format("15:04:05")
+		}
+		duration := ""
+		if child.Task.Duration > 0 {
+			duration = " (" + child.Task.Duration.Round(time.Millisecond).String() + ")"
+		}
+		status := statusStyleFor(child.Status).Render(strings.ToUpper(child.Status)) +
+			strings.Repeat(" ", max(len("UNREACHABLE")-len(child.Status), 0))
+		fmt.Fprintf(&b, "%s %s [%d] %s%s\n", started, status, child.Task.ID, child.Name, duration)
+	}
+	return b.String()
+}
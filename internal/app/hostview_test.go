@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHostView(t *testing.T) {
+	log := "TASK [Install] ***\n" +
+		"changed: [web1] => (item=a)\nok: [web1] => (item=b)\nok: [web2]\n\n" +
+		"TASK [Wait for port] ***\n" +
+		"ok: [web1]\n" +
+		`fatal: [web2]: UNREACHABLE! => {"changed": false, "msg": "Failed to connect", "unreachable": true}` + "\n\n" +
+		"TASK [Check] ***\n" +
+		`fatal: [web1]: FAILED! => {"changed": false, "msg": "boom"}` + "\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.toggleHostView()
+	if len(m.flatNodes) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(m.flatNodes))
+	}
+	web1 := m.flatNodes[0].node
+	// Loop items count once, with the most severe status
+	if web1.Name != "web1" || web1.Status != "failed" || web1.Recap != "ok=2 changed=1 unreachable=0 failed=1 skipped=0" {
+		t.Errorf("web1 = %q %q %q", web1.Name, web1.Status, web1.Recap)
+	}
+	if web2 := m.flatNodes[1].node; web2.Status != "unreachable" || len(web2.Children) != 2 {
+		t.Errorf("web2 = %q with %d tasks", web2.Status, len(web2.Children))
+	}
+
+	web1.IsExpanded = true
+	m.rebuildFlatNodes()
+	var names []string
+	for _, fn := range m.flatNodes[1:4] {
+		names = append(names, fmt.Sprintf("%d:%s:%s", fn.depth, fn.node.Name, fn.node.Status))
+	}
+	if got, want := strings.Join(names, " "), "1:Install:changed 1:Wait for port:ok 1:Check:fatal"; got != want {
+		t.Errorf("web1 tasks = %q, want %q", got, want)
+	}
+	if summary := stripANSI(renderHostSummary(web1)); !strings.Contains(summary, "FATAL       [3] Check") {
+		t.Errorf("host summary =\n%s", summary)
+	}
+
+	// Filters keep the hosts that ran a matching task, with those tasks
+	m.filterNodes("status:unreachable")
+	if len(m.filteredNodes) != 1 || m.filteredNodes[0].Name != "web2" || len(m.filteredNodes[0].Children) != 1 {
+		t.Errorf("filtered hosts = %+v", m.filteredNodes)
+	}
+	m.filterNodes("")
+	m.toggleHostView()
+	if len(m.flatNodes) != 3 || m.flatNodes[0].node.Task == nil {
+		t.Errorf("task view has %d nodes after leaving the host view", len(m.flatNodes))
+	}
+}
+
+func TestHostViewJump(t *testing.T) {
+	log := "TASK [Install] ***\nchanged: [web1]\nchanged: [web2]\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.toggleHostView()
+	for i := range m.hostNodes {
+		m.hostNodes[i].IsExpanded = true
+	}
+	m.filterNodes("")
+	// Both hosts list the task, each under its own node
+	if len(m.flatNodes) != 4 || m.flatNodes[1].node.ID == m.flatNodes[3].node.ID {
+		t.Fatalf("flat nodes = %+v", m.flatNodes)
+	}
+	for _, step := range []struct {
+		selected int
+		status   string
+	}{
+		{1, "change 1/2"},
+		{3, "change 2/2"},
+		{1, "change 1/2"},
+	} {
+		m.jumpKey("c")
+		if m.selected != step.selected || m.statusMessage != step.status {
+			t.Errorf("selected %d with %q, want %d with %q", m.selected, m.statusMessage, step.selected, step.status)
+		}
+	}
+	if m.flatNodes[3].node.Task.ID != tasks[0].ID || !strings.Contains(stripANSI(m.renderNodeList()), "[1] Install") {
+		t.Errorf("host view shows task ID %d", m.flatNodes[3].node.Task.ID)
+	}
+}
+
+func TestHostViewDelegation(t *testing.T) {
+	log := "TASK [Install] ***\nchanged: [web01]\n\n" +
+		"TASK [Add to load balancer] ***\n" +
+		`fatal: [web01 -> localhost]: FAILED! => {"changed": false, "msg": "boom"}` + "\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	nodes := convertTasksToHostNodes([]*Task{&tasks[0], &tasks[1]})
+	if len(nodes) != 1 || nodes[0].Name != "web01" || len(nodes[0].Children) != 2 || nodes[0].Status != "failed" {
+		t.Fatalf("host nodes = %+v", nodes)
+	}
+	// The result keeps the host it was delegated to
+	if host := nodes[0].Children[1].Task.Hosts[0].Host; host != "web01 -> localhost" {
+		t.Errorf("delegated result of %q", host)
+	}
+
+	items := buildDashboard([]*Task{&tasks[0], &tasks[1]})
+	var failed []string
+	for _, item := range items {
+		if item.section == "Hosts with failures" {
+			failed = append(failed, item.text)
+		}
+	}
+	if len(failed) != 1 || !strings.HasPrefix(failed[0], "web01 ") {
+		t.Errorf("hosts with failures = %q", failed)
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// indexVersion must be bumped whenever the parser changes what it extracts
+// from a log, so that index files written by older versions are reparsed.
+const indexVersion = 17
+
+// indexHashSize is the number of bytes hashed at the head and tail of a file.
+const indexHashSize = 64 * 1024
+
+// parseIndex is the on-disk record of a parsed log file. A file is identified
+// by its path, size, modification time and hashes of its first and last bytes;
+// the tasks carry their boundaries, statuses, hosts and byte offsets.
+type parseIndex struct {
+	Version  int
+	Path     string
+	Size     int64 // Number of bytes of the file that were parsed
+	ModTime  time.Time
+	HeadHash string
+	TailHash string
+	Rules    string // Fingerprint of the user-defined rules used for parsing
+	Tasks    []Task
+}
+
+// DefaultIndexCacheDir returns the directory index files are stored in by
+// default, $XDG_CACHE_HOME/ansible-logs-view (usually ~/.cache/ansible-logs-view).
+func DefaultIndexCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ansible-logs-view"), nil
+}
+
+// newParseIndex describes the current state of the file behind source, to
+// be parsed with the rules identified by rules.
+func newParseIndex(source *logSource, rules string) (*parseIndex, error) {
+	info, err := source.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(source.path)
+	if err != nil {
+		return nil, err
+	}
+	idx := &parseIndex{
+		Version: indexVersion,
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Rules:   rules,
+	}
+	if idx.HeadHash, err = hashRange(source, 0, idx.Size); err != nil {
+		return nil, err
+	}
+	if idx.TailHash, err = hashRange(source, idx.Size-indexHashSize, idx.Size); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// hashRange hashes at most indexHashSize bytes of source starting at start,
+// without reading past end.
+func hashRange(source *logSource, start, end int64) (string, error) {
+	if start < 0 {
+		start = 0
+	}
+	if end-start > indexHashSize {
+		end = start + indexHashSize
+	}
+	data, err := source.readRange(start, end)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// matches reports whether cached describes exactly the same file as idx.
+func (idx *parseIndex) matches(cached *parseIndex) bool {
+	return cached.Version == indexVersion &&
+		cached.Path == idx.Path &&
+		cached.Rules == idx.Rules &&
+		cached.Size == idx.Size &&
+		cached.ModTime.Equal(idx.ModTime) &&
+		cached.HeadHash == idx.HeadHash &&
+		cached.TailHash == idx.TailHash
+}
+
+// extends reports whether the file behind idx is the file described by cached
+// with data appended to it, so parsing can resume where the index ends.
+func (idx *parseIndex) extends(cached *parseIndex, source *logSource) bool {
+	if cached.Version != indexVersion || cached.Path != idx.Path || cached.Rules != idx.Rules || cached.Size >= idx.Size {
+		return false
+	}
+	head, err := hashRange(source, 0, cached.Size)
+	if err != nil || head != cached.HeadHash {
+		return false
+	}
+	tail, err := hashRange(source, cached.Size-indexHashSize, cached.Size)
+	return err == nil && tail == cached.TailHash
+}
+
+// indexFile returns the index file location for the log at path.
+func indexFile(dir, path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".idx")
+}
+
+// loadParseIndex reads the index stored for path, or returns nil if there is
+// none or it can't be decoded.
+func loadParseIndex(dir, path string) *parseIndex {
+	file, err := os.Open(indexFile(dir, path))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var idx parseIndex
+	if err := gob.NewDecoder(file).Decode(&idx); err != nil {
+		debugLog.Printf("loadParseIndex() - Ignoring unreadable index for %s: %v", path, err)
+		return nil
+	}
+	return &idx
+}
+
+// save writes the index to dir, replacing any previous index of the same file.
+func (idx *parseIndex) save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(idx); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error encoding index: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), indexFile(dir, idx.Path))
+}
@@ -0,0 +1,420 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	jsonKeyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FAFFF"))
+	jsonStringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065"))
+	jsonNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
+	jsonBoolStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#D75FD7"))
+	jsonNullStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676"))
+	jsonTypeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676")).Italic(true)
+	jsonCursorStyle = lipgloss.NewStyle().Reverse(true)
+)
+
+// jsonIdentifierRegex matches object keys that can be written as ".key" in a
+// JSON path.
+var jsonIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonNode is a value of a result payload in the result tree. Objects keep
+// the order of their keys as the module returned them.
+type jsonNode struct {
+	key      string // Object key, array index or host name of the node
+	kind     string // "object", "array", "string", "number", "bool" or "null"
+	value    string // JSON text of a scalar
+	path     string // JSON path of the node within its host's result
+	children []*jsonNode
+	expanded bool
+}
+
+// jsonTree is a collapsible view of the result payloads of a task, with one
+// root per host.
+type jsonTree struct {
+	roots  []*jsonNode
+	cursor int    // Index of the selected line among the visible nodes
+	filter string // Lower-case text the keys of visible nodes must contain
+	// matched holds the nodes shown while filtering: those whose key
+	// contains the filter and their ancestors
+	matched map[*jsonNode]bool
+}
+
+// jsonLine is a visible node of a jsonTree and its depth.
+type jsonLine struct {
+	node  *jsonNode
+	depth int
+}
+
+// newJSONTree builds the result tree of task, loading the payloads of its
+// host results and hiding secrets in their values with redact. Hosts without
+// a payload are left out.
+func newJSONTree(task *Task, redact func(string) string) (*jsonTree, error) {
+	tree := &jsonTree{}
+	for i, result := range task.Hosts {
+		text, err := task.LoadResult(i)
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			continue
+		}
+		root, err := parseJSONNode(json.NewDecoder(strings.NewReader(text)), "$", redact)
+		if err != nil {
+			debugLog.Printf("newJSONTree() - Error decoding result of %s: %v", result.Host, err)
+			continue
+		}
+		root.key = fmt.Sprintf("%s (%s)", result.Host, result.Status)
+		root.expanded = true
+		tree.roots = append(tree.roots, root)
+	}
+	return tree, nil
+}
+
+// parseJSONNode reads the next JSON value from decoder into a node with the
+// given path, passing scalar values through redact. Nodes start collapsed.
+func parseJSONNode(decoder *json.Decoder, path string, redact func(string) string) (*jsonNode, error) {
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &jsonNode{path: path}
+	switch value := token.(type) {
+	case json.Delim:
+		node.kind = "array"
+		if value == '{' {
+			node.kind = "object"
+		}
+		for index := 0; decoder.More(); index++ {
+			key := strconv.Itoa(index)
+			childPath := fmt.Sprintf("%s[%d]", path, index)
+			if node.kind == "object" {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyToken.(string)
+				childPath = jsonPathKey(path, key)
+			}
+			child, err := parseJSONNode(decoder, childPath, redact)
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			if node.kind == "object" && !child.container() {
+				child.value = redactField(key, child.value, redact)
+			}
+			node.children = append(node.children, child)
+		}
+		// Closing delimiter
+		if _, err := decoder.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+	case string:
+		node.kind = "string"
+		data, _ := json.Marshal(value)
+		node.value = string(data)
+	case json.Number:
+		node.kind = "number"
+		node.value = value.String()
+	case bool:
+		node.kind = "bool"
+		node.value = strconv.FormatBool(value)
+	case nil:
+		node.kind = "null"
+		node.value = "null"
+	}
+	if node.value != "" {
+		node.value = redact(node.value)
+	}
+	return node, nil
+}
+
+// redactField hides value, the JSON text of a scalar, if it is a secret
+// because of its key, as in "password": "...". Values are redacted on their
+// own before they're styled, where the key no longer precedes them.
+func redactField(key, value string, redact func(string) string) string {
+	keyText, _ := json.Marshal(key)
+	prefix := string(keyText) + ": "
+	if redacted := redact(prefix + value); redacted != prefix+value && strings.HasPrefix(redacted, prefix) {
+		return strings.TrimPrefix(redacted, prefix)
+	}
+	return value
+}
+
+// jsonPathKey returns the JSON path of key in the object at path.
+func jsonPathKey(path, key string) string {
+	if jsonIdentifierRegex.MatchString(key) {
+		return path + "." + key
+	}
+	data, _ := json.Marshal(key)
+	return path + "[" + string(data) + "]"
+}
+
+// container reports whether the node has children to expand.
+func (n *jsonNode) container() bool {
+	return n.kind == "object" || n.kind == "array"
+}
+
+// setFilter shows only the nodes whose key contains filter, ignoring case,
+// with their ancestors expanded. An empty filter shows all nodes again.
+func (t *jsonTree) setFilter(filter string) {
+	t.filter = strings.ToLower(filter)
+	t.matched = nil
+	t.cursor = 0
+	if t.filter == "" {
+		return
+	}
+	t.matched = make(map[*jsonNode]bool)
+	var match func(node *jsonNode, isRoot bool) bool
+	match = func(node *jsonNode, isRoot bool) bool {
+		found := !isRoot && strings.Contains(strings.ToLower(node.key), t.filter)
+		for _, child := range node.children {
+			if match(child, false) {
+				found = true
+			}
+		}
+		if found {
+			t.matched[node] = true
+		}
+		return found
+	}
+	for _, root := range t.roots {
+		match(root, true)
+	}
+}
+
+// lines returns the visible nodes in display order.
+func (t *jsonTree) lines() []jsonLine {
+	var lines []jsonLine
+	var walk func(node *jsonNode, depth int)
+	walk = func(node *jsonNode, depth int) {
+		if t.matched != nil && !t.matched[node] {
+			return
+		}
+		lines = append(lines, jsonLine{node, depth})
+		if t.expandedInView(node) {
+			for _, child := range node.children {
+				walk(child, depth+1)
+			}
+		}
+	}
+	for _, root := range t.roots {
+		walk(root, 0)
+	}
+	return lines
+}
+
+// expandedInView reports whether the children of node are shown. While
+// filtering, nodes leading to matches are always expanded.
+func (t *jsonTree) expandedInView(node *jsonNode) bool {
+	if t.matched != nil {
+		for _, child := range node.children {
+			if t.matched[child] {
+				return true
+			}
+		}
+	}
+	return node.expanded
+}
+
+// selected returns the node under the cursor, or nil if nothing is visible.
+func (t *jsonTree) selected() *jsonNode {
+	lines := t.lines()
+	if len(lines) == 0 {
+		return nil
+	}
+	t.cursor = min(max(t.cursor, 0), len(lines)-1)
+	return lines[t.cursor].node
+}
+
+// move moves the cursor by step lines, stopping at the first and last line.
+func (t *jsonTree) move(step int) {
+	t.cursor = min(max(t.cursor+step, 0), max(len(t.lines())-1, 0))
+}
+
+// setExpanded expands or collapses the selected node. Collapsing a node
+// that is collapsed already, or has no children, selects its parent.
+func (t *jsonTree) setExpanded(expanded bool) {
+	node := t.selected()
+	if node == nil {
+		return
+	}
+	if node.container() && node.expanded != expanded {
+		node.expanded = expanded
+		return
+	}
+	if expanded {
+		return
+	}
+	lines := t.lines()
+	for i := t.cursor - 1; i >= 0; i-- {
+		if lines[i].depth < lines[t.cursor].depth {
+			t.cursor = i
+			return
+		}
+	}
+}
+
+// expandAll expands or collapses all nodes below the roots.
+func (t *jsonTree) expandAll(expanded bool) {
+	var walk func(node *jsonNode)
+	walk = func(node *jsonNode) {
+		node.expanded = expanded
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	for _, root := range t.roots {
+		walk(root)
+		root.expanded = true
+	}
+	t.move(0)
+}
+
+// render renders the visible nodes, each cut to width columns, highlighting
+// the cursor when the tree has focus.
+func (t *jsonTree) render(width int, focused bool) string {
+	if len(t.roots) == 0 {
+		return "No result recorded for this task. Results are shown for multi-line payloads, or from -v on.\n"
+	}
+	lines := t.lines()
+	if len(lines) == 0 {
+		return fmt.Sprintf("No keys match %q.\n", t.filter)
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		node := line.node
+		marker := "  "
+		if node.container() {
+			marker = "▸ "
+			if t.expandedInView(node) {
+				marker = "▾ "
+			}
+		}
+		prefix := strings.Repeat("  ", line.depth) + marker
+		key := node.key
+		if line.depth > 0 {
+			key += ": "
+		} else {
+			key += " "
+		}
+
+		var value, typeName string
+		var valueStyle lipgloss.Style
+		switch node.kind {
+		case "object":
+			value, typeName, valueStyle = fmt.Sprintf("{%d}", len(node.children)), "object", jsonTypeStyle
+		case "array":
+			value, typeName, valueStyle = fmt.Sprintf("[%d]", len(node.children)), "array", jsonTypeStyle
+		default:
+			value, typeName = node.value, node.kind
+			valueStyle = map[string]lipgloss.Style{
+				"string": jsonStringStyle, "number": jsonNumberStyle, "bool": jsonBoolStyle, "null": jsonNullStyle,
+			}[node.kind]
+		}
+		typeName = "  " + typeName
+
+		// Long values are cut so that every node takes a single line
+		room := width - len([]rune(prefix+key+typeName))
+		if valueRunes := []rune(value); len(valueRunes) > room {
+			value = string(valueRunes[:max(room-1, 0)]) + "…"
+		}
+
+		keyText := jsonKeyStyle.Render(key)
+		if focused && i == t.cursor {
+			keyText = jsonCursorStyle.Render(key)
+		}
+		b.WriteString(prefix + keyText + valueStyle.Render(value) + jsonTypeStyle.Render(typeName) + "\n")
+	}
+	return b.String()
+}
+
+// currentResultTree returns the result tree of node, building it when the
+// selection moved to another task since it was last shown, or secrets were
+// shown or hidden.
+func (m *Model) currentResultTree(node *TreeNode) *jsonTree {
+	if m.resultTree != nil && m.resultTreeTask == node.Task {
+		return m.resultTree
+	}
+	tree := &jsonTree{}
+	if node.Task != nil {
+		var err error
+		if tree, err = newJSONTree(node.Task, m.redact); err != nil {
+			debugLog.Printf("currentResultTree() - Error loading results of node %d: %v", node.ID, err)
+			m.statusMessage = fmt.Sprintf("Error loading result: %v", err)
+			tree = &jsonTree{}
+		}
+	}
+	// The key filter carries over to the results of other tasks
+	tree.setFilter(m.resultFilterInput.Value())
+	m.resultTree = tree
+	m.resultTreeTask = node.Task
+	return tree
+}
+
+// updateResultTree handles a key pressed while the result tree has focus and
+// reports whether the key was used.
+func (m *Model) updateResultTree(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if len(m.flatNodes) == 0 {
+		return false, nil
+	}
+	tree := m.currentResultTree(m.flatNodes[m.selected].node)
+	switch msg.String() {
+	case "tab", "esc":
+		m.resultFocused = false
+	case "down", "j":
+		tree.move(1)
+	case "up", "k":
+		tree.move(-1)
+	case "right", "l", "enter", " ":
+		tree.setExpanded(true)
+	case "left", "h":
+		tree.setExpanded(false)
+	case "e":
+		tree.expandAll(true)
+	case "E":
+		tree.expandAll(false)
+	case "y":
+		if node := tree.selected(); node != nil {
+			if err := copyToClipboard(node.path); err != nil {
+				m.statusMessage = fmt.Sprintf("Copy failed: %v", err)
+			} else {
+				m.statusMessage = "Copied " + node.path
+			}
+		}
+	case "/":
+		m.showingResultFilter = true
+		m.resultFilterInput.Focus()
+		m.updateViewports()
+		return true, textinput.Blink
+	default:
+		return false, nil
+	}
+	m.updateDetailsViewportContent()
+	return true, nil
+}
+
+// copyToClipboard puts text on the system clipboard, falling back to the
+// OSC 52 escape sequence, which also works over SSH, when no clipboard tool
+// is installed.
+func copyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jumpKind is a kind of task that can be jumped to from anywhere in the task
+// list, without filtering away the other tasks.
+type jumpKind struct {
+	name   string // Name shown in the status bar, "failure 3/7"
+	plural string // Name shown when there are none, "No failures"
+	match  func(task *Task) bool
+}
+
+// jumpKinds maps the lower-case keys jumping to the next task of a kind to
+// the kind; the upper-case keys jump to the previous one.
+var jumpKinds = map[string]jumpKind{
+	"f": {"failure", "failures", func(task *Task) bool { return task.hasHostStatus("failed", "fatal") }},
+	"c": {"change", "changes", func(task *Task) bool { return task.hasHostStatus("changed") }},
+	"u": {"unreachable", "unreachable hosts", func(task *Task) bool { return task.hasHostStatus("unreachable") }},
+	"w": {"warning", "warnings", func(task *Task) bool { return len(task.Warnings) > 0 }},
+	"r": {"retry", "retries", func(task *Task) bool { return task.Retries > 0 }},
+}
+
+// hasHostStatus reports whether the task or one of its host results has one
+// of statuses.
+func (t *Task) hasHostStatus(statuses ...string) bool {
+	for _, status := range statuses {
+		if t.Status == status {
+			return true
+		}
+		for _, result := range t.Hosts {
+			if result.Status == status {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jump selects the next task of kind after the selected one, or the
+// previous one for a negative step, wrapping around at the end of the list,
+// and shows its position among all tasks of the kind in the status bar.
+func (m *Model) jump(kind jumpKind, step int) {
+	var matches []int // Indexes into m.flatNodes
+	for i, fn := range m.flatNodes {
+		if fn.node.Task != nil && kind.match(fn.node.Task) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		m.statusMessage = "No " + kind.plural
+		return
+	}
+
+	// With step 1 the first match after the selection, with step -1 the
+	// last one before it
+	target := -1
+	if step > 0 {
+		for n, i := range matches {
+			if i > m.selected {
+				target = n
+				break
+			}
+		}
+		if target < 0 {
+			target = 0
+		}
+	} else {
+		for n, i := range matches {
+			if i < m.selected {
+				target = n
+			}
+		}
+		if target < 0 {
+			target = len(matches) - 1
+		}
+	}
+	debugLog.Printf("jump() - Jumping to %s %d/%d at index %d", kind.name, target+1, len(matches), matches[target])
+	m.selectIndex(matches[target])
+	m.statusMessage = fmt.Sprintf("%s %d/%d", kind.name, target+1, len(matches))
+}
+
+// jumpKey jumps to the next or previous task of the kind of key, one of the
+// keys of jumpKinds or their upper-case forms.
+func (m *Model) jumpKey(key string) {
+	step := 1
+	if key == strings.ToUpper(key) {
+		step = -1
+	}
+	m.jump(jumpKinds[strings.ToLower(key)], step)
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestJumpNavigation(t *testing.T) {
+	log := "TASK [Install] ***\n" +
+		"[WARNING]: Consider using the yum module rather than running 'yum'.\n" +
+		"changed: [web1]\nok: [web2]\n\n" +
+		"TASK [Wait for port] ***\n" +
+		"FAILED - RETRYING: [web1]: Wait for port (3 retries left).\n" +
+		"FAILED - RETRYING: [web1]: Wait for port (2 retries left).\n" +
+		"ok: [web1]\n" +
+		`fatal: [web2]: UNREACHABLE! => {"changed": false, "msg": "Failed to connect", "unreachable": true}` + "\n\n" +
+		"TASK [Check] ***\n" +
+		`fatal: [web1]: FAILED! => {"changed": false, "msg": "boom"}` + "\n" +
+		`changed: [web3] => {"changed": true, "warnings": ["Consider using the yum module rather than running 'yum'.", "second"]}` + "\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+	if len(tasks[0].Warnings) != 1 || tasks[1].Retries != 2 || tasks[1].Hosts[1].Status != "unreachable" {
+		t.Errorf("warnings %q, retries %d, status %q", tasks[0].Warnings, tasks[1].Retries, tasks[1].Hosts[1].Status)
+	}
+	// Warnings of results are only added once
+	if len(tasks[2].Warnings) != 2 || tasks[2].Warnings[1] != "second" {
+		t.Errorf("result warnings %q", tasks[2].Warnings)
+	}
+
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	for _, step := range []struct {
+		key      string
+		selected int
+		status   string
+	}{
+		{"c", 2, "change 2/2"},
+		{"c", 0, "change 1/2"},
+		{"C", 2, "change 2/2"},
+		{"F", 2, "failure 1/1"},
+		{"u", 1, "unreachable 1/1"},
+		{"r", 1, "retry 1/1"},
+		{"W", 0, "warning 1/2"},
+	} {
+		m.jumpKey(step.key)
+		if m.selected != step.selected || m.statusMessage != step.status {
+			t.Errorf("%s: selected %d with %q, want %d with %q", step.key, m.selected, m.statusMessage, step.selected, step.status)
+		}
+	}
+
+	m = NewModel(tasks[:1], false)
+	m.jumpKey("f")
+	if m.statusMessage != "No failures" {
+		t.Errorf("status %q without failures", m.statusMessage)
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shortHelpText is the help line under the task list, kept to the keys used
+// most so that it fits on one line. The others are listed by F1.
+const shortHelpText = "j/k: move • /: filter • ?: search • D: dashboard • F1: all keys • q: quit"
+
+// keyHelp lists every key of the task list, as shown by F1.
+var keyHelp = [][2]string{
+	{"j/k, up/down", "move"},
+	{"g/G", "go to first/last line"},
+	{"pgup/pgdown", "scroll the task list"},
+	{"enter, space", "expand/collapse"},
+	{"ctrl+j/k", "scroll details"},
+	{"/", "filter, tab in the filter changes mode"},
+	{"?", "search raw task output"},
+	{"n/N", "next/previous match"},
+	{"f/c/u/w/r", "next failure/change/unreachable/warning/retry"},
+	{"F/C/U/W/R", "previous failure/change/unreachable/warning/retry"},
+	{"[/], 1-5", "details tab"},
+	{"tab", "browse the result tree, tab again to leave it"},
+	{"e/E", "expand/collapse all of the result tree"},
+	{"y, /", "copy the path of a result key, filter result keys"},
+	{"v", "side-by-side diff"},
+	{"h/l", "scroll side-by-side diff"},
+	{"x", "expand long lines"},
+	{"a", "ansi colours"},
+	{"ctrl+r", "show/hide secrets"},
+	{"o/O", "open in pager/editor"},
+	{"H", "host view"},
+	{"D", "dashboard"},
+	{"q", "quit"},
+}
+
+// renderKeyHelp renders keyHelp as a table of keys and what they do.
+func renderKeyHelp() string {
+	width := 0
+	for _, k := range keyHelp {
+		width = max(width, len(k[0]))
+	}
+	var b strings.Builder
+	b.WriteString(dashboardSectionStyle.Render("Keys") + "\n")
+	for _, k := range keyHelp {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, k[0], k[1])
+	}
+	return b.String()
+}
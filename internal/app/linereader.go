@@ -0,0 +1,42 @@
+package app
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// lineReader reads a log file line by line. Unlike bufio.Scanner it has no
+// token size limit, so a single multi-megabyte result line (large registered
+// stdout, slurp output, ...) is read in full instead of aborting the parse.
+// It also tracks byte offsets so tasks can refer back into the file.
+type lineReader struct {
+	r         *bufio.Reader
+	offset    int64 // offset of the next unread byte
+	lineStart int64 // offset of the line last returned by next
+	lineNo    int   // 1-based number of the line last returned by next
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// next returns the next line without its trailing "\n" or "\r\n". It returns
+// io.EOF once the input is exhausted; a final line without a newline is still
+// returned before that.
+func (lr *lineReader) next() (string, error) {
+	line, err := lr.r.ReadString('\n')
+	lr.lineStart = lr.offset
+	lr.offset += int64(len(line))
+	if line != "" {
+		lr.lineNo++
+	}
+	if err != nil {
+		if err == io.EOF && line != "" {
+			return strings.TrimSuffix(line, "\r"), nil
+		}
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
@@ -9,7 +9,8 @@ import (
 )
 
 var (
-	debugLog   *log.Logger
+	// debugLog discards output until setupLogger is called
+	debugLog   = log.New(io.Discard, "", 0)
 	loggerMux  sync.Mutex
 	loggerOnce sync.Once
 )
@@ -1,19 +1,42 @@
 package app
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
 
+// statusLineRegex matches the lines reporting a host result.
+var statusLineRegex = regexp.MustCompile(`^(ok|changed|skipping|failed|fatal): \[`)
+
+var (
+	// Warnings printed while a task runs, "[WARNING]: ..." and
+	// "[DEPRECATION WARNING]: ..."
+	warningRegex = regexp.MustCompile(`^\[(?:DEPRECATION )?WARNING\]: (.*)`)
+	// Attempts of a task with "until" that failed and are retried,
+	// "FAILED - RETRYING: [web01]: Wait for port (3 retries left)."
+	retryRegex = regexp.MustCompile(`^FAILED - RETRYING: `)
+)
+
 // LogParser handles parsing of Ansible log files
 type LogParser struct {
-	tasks []Task
+	tasks    []Task
+	source   *logSource
+	size     int64  // Size of the log file when it was last parsed
+	cacheDir string // Directory for parse index files, empty disables caching
+	rules    []LineRule
+	unsaved  *parseIndex // Index of the last parse, until it is saved
+	savedAt  time.Time
 }
 
+// indexSaveInterval is how often the index is saved while a log is
+// followed, as encoding the index of a big log takes a while.
+const indexSaveInterval = time.Minute
+
 // logger initialization is centralized in logger.go
 
 // NewLogParser creates a new LogParser instance
@@ -24,23 +47,199 @@ func NewLogParser(enableDebug bool) *LogParser {
 	}
 }
 
-// ParseFile parses an Ansible log file and extracts tasks
+// Close releases the log file kept open for lazily loading raw task text,
+// saving the index of any tasks followed since it was last saved. Tasks
+// returned by ParseFile can no longer load their text afterwards.
+func (p *LogParser) Close() error {
+	p.saveIndex()
+	if p.source == nil {
+		return nil
+	}
+	err := p.source.Close()
+	p.source = nil
+	return err
+}
+
+// SetIndexCacheDir enables the persistent parse index: ParseFile stores the
+// parsed tasks of every file under dir and reuses them when the same file is
+// opened again. An empty dir disables the cache.
+func (p *LogParser) SetIndexCacheDir(dir string) {
+	p.cacheDir = dir
+}
+
+// SetRules sets user-defined line rules, applied to every line of a task in
+// addition to the built-in parsing. Rules must come from LoadConfig.
+func (p *LogParser) SetRules(rules []LineRule) {
+	p.rules = rules
+}
+
+// ParseFile parses an Ansible log file and extracts tasks. Tasks record the
+// byte range they occupy in the file rather than a copy of their text; the
+// file stays open until Close so the text can be loaded on demand.
+//
+// With an index cache configured, an unchanged file is not parsed at all and
+// a file that only grew since it was indexed is parsed from its last task.
 func (p *LogParser) ParseFile(filename string) ([]Task, error) {
-	file, err := os.Open(filename)
+	source, err := openLogSource(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error opening file: %v", err)
 	}
-	defer file.Close()
+	// A parser serves lazy loads for the last parsed file only
+	p.Close()
+	p.source = source
+	p.tasks = nil
+
+	var idx *parseIndex
+	start, taskID, startLine, play := int64(0), 1, 1, ""
+	if p.cacheDir != "" {
+		idx, err = newParseIndex(source, rulesFingerprint(p.rules))
+		if err != nil {
+			return nil, fmt.Errorf("error indexing file: %v", err)
+		}
+		if cached := loadParseIndex(p.cacheDir, idx.Path); cached != nil {
+			switch {
+			case idx.matches(cached):
+				debugLog.Printf("ParseFile() - Using cached index for %s (%d tasks)", idx.Path, len(cached.Tasks))
+				p.tasks = cached.Tasks
+				p.size = cached.Size
+				p.attachSource()
+				return p.tasks, nil
+			case idx.extends(cached, source):
+				p.tasks = cached.Tasks
+				start, taskID, startLine, play = p.resumePoint()
+				debugLog.Printf("ParseFile() - Resuming cached index for %s at offset %d", idx.Path, start)
+			}
+		}
+	}
+
+	if err := p.parseFrom(start, taskID, startLine, play, idx); err != nil {
+		return nil, err
+	}
+	p.saveIndex()
+	return p.tasks, nil
+}
+
+// Follow parses what was appended to the log file since it was last parsed,
+// resuming from the last parsed task as ParseFile does from the last indexed
+// one, and reports whether the tasks changed. A file that was truncated or
+// replaced, as by log rotation, is parsed again from the start.
+func (p *LogParser) Follow() ([]Task, bool, error) {
+	if p.source == nil {
+		return nil, false, fmt.Errorf("no log file to follow")
+	}
+	info, err := os.Stat(p.source.path)
+	if err != nil {
+		return p.tasks, false, fmt.Errorf("error following file: %v", err)
+	}
+	opened, err := p.source.file.Stat()
+	if err != nil {
+		return p.tasks, false, fmt.Errorf("error following file: %v", err)
+	}
+	if !os.SameFile(info, opened) || info.Size() < p.size {
+		debugLog.Printf("Follow() - %s was replaced or truncated, parsing it again", p.source.path)
+		tasks, err := p.ParseFile(p.source.path)
+		return tasks, err == nil, err
+	}
+	if info.Size() == p.size {
+		return p.tasks, false, nil
+	}
+
+	var idx *parseIndex
+	if p.cacheDir != "" {
+		if idx, err = newParseIndex(p.source, rulesFingerprint(p.rules)); err != nil {
+			return p.tasks, false, fmt.Errorf("error indexing file: %v", err)
+		}
+	}
+	// Tasks returned before stay as they were
+	p.tasks = slices.Clone(p.tasks)
+	start, taskID, startLine, play := p.resumePoint()
+	debugLog.Printf("Follow() - Resuming %s at offset %d", p.source.path, start)
+	if err := p.parseFrom(start, taskID, startLine, play, idx); err != nil {
+		return p.tasks, false, err
+	}
+	if time.Since(p.savedAt) >= indexSaveInterval {
+		p.saveIndex()
+	}
+	return p.tasks, true, nil
+}
+
+// resumePoint drops the last parsed task, which may have been incomplete,
+// and returns the offset, task ID, line number and play to parse it again
+// from.
+func (p *LogParser) resumePoint() (start int64, taskID, startLine int, play string) {
+	n := len(p.tasks)
+	if n == 0 {
+		return 0, 1, 1, ""
+	}
+	last := p.tasks[n-1]
+	p.tasks = p.tasks[:n-1]
+	return last.StartOffset, last.ID, last.StartLine, last.Play
+}
 
-	scanner := bufio.NewScanner(file)
+// parseFrom parses the log file from offset start, appending to the tasks
+// parsed so far, and records them in the index idx, if it isn't nil, to be
+// saved by saveIndex.
+func (p *LogParser) parseFrom(start int64, taskID, startLine int, play string, idx *parseIndex) error {
+	info, err := p.source.file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading file: %v", err)
+	}
+	if err := p.parse(p.source, start, taskID, startLine, play); err != nil {
+		return err
+	}
+	p.size = info.Size()
+	fillDurations(p.tasks)
+	p.attachSource()
+
+	if idx != nil {
+		idx.Tasks = p.tasks
+		p.unsaved = idx
+	}
+	return nil
+}
+
+// saveIndex writes the index recorded by the last parse to the cache, if it
+// wasn't saved yet.
+func (p *LogParser) saveIndex() {
+	if p.unsaved == nil {
+		return
+	}
+	if err := p.unsaved.save(p.cacheDir); err != nil {
+		debugLog.Printf("saveIndex() - Error saving index: %v", err)
+	}
+	p.unsaved = nil
+	p.savedAt = time.Now()
+}
+
+// attachSource points all parsed tasks at the current log source.
+func (p *LogParser) attachSource() {
+	for i := range p.tasks {
+		p.tasks[i].source = p.source
+	}
+}
+
+// parse reads the log from offset start, which is at line number startLine
+// in the play named play, appending tasks numbered from taskID.
+func (p *LogParser) parse(source *logSource, start int64, taskID, startLine int, play string) error {
+	if _, err := source.file.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading file: %v", err)
+	}
+	// Sequential reads don't disturb the ReadAt calls used for lazy loading
+	reader := newLineReader(source.file)
+	reader.offset = start
+	reader.lineNo = startLine - 1
 	var currentTask *Task
-	taskID := 1
+	// Index into currentTask.Hosts of the result still collecting lines, or -1
+	openResult := -1
+	// JSON payload lines of the open result, decoded when the result ends
+	var payload []string
+	// Host of the last verbose line, for lines that don't name their host
+	verboseHost := ""
 
 	taskRegex := regexp.MustCompile(`^TASK \[(.*?)\] \*+$`)
+	playRegex := regexp.MustCompile(`^PLAY \[(.*?)\] \*+$`)
 	startedRegex := regexp.MustCompile(`\[started TASK: (.*?) on (.*?)\]`)
 	pathRegex := regexp.MustCompile(`task path: (.*)`)
-	// Time format: Tuesday 28 October 2025  02:05:23 +0100
-	timeRegex := regexp.MustCompile(`^(\w+) (\d+) (\w+) (\d+)  (\d+):(\d+):(\d+)`)
 
 	// Status regexes
 	okRegex := regexp.MustCompile(`^ok: \[(.*?)\]`)
@@ -49,103 +248,171 @@ func (p *LogParser) ParseFile(filename string) ([]Task, error) {
 	failedRegex := regexp.MustCompile(`^failed: \[(.*?)\]`)
 	fatalRegex := regexp.MustCompile(`^fatal: \[(.*?)\]`)
 
-	// Diff regexes
-	diffStartRegex := regexp.MustCompile(`^--- before:`)
-
-	// Map month names to numbers for parsing
-	monthMap := map[string]string{
-		"January": "01", "February": "02", "March": "03", "April": "04",
-		"May": "05", "June": "06", "July": "07", "August": "08",
-		"September": "09", "October": "10", "November": "11", "December": "12",
-	}
-
 	// Variables for diff parsing
 	inDiffSection := false
 	var diffLines []string
+	// Diff printed last, which belongs to the host result reported next
+	pendingDiff := ""
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	// endDiff adds the diff collected in diffLines to the current task
+	endDiff := func() {
+		if len(diffLines) > 0 {
+			diff := strings.Join(diffLines, "\n")
+			if currentTask.Diff != "" {
+				currentTask.Diff += "\n" + diff
+			} else {
+				currentTask.Diff = diff
+			}
+			pendingDiff = diff
+		}
+		inDiffSection = false
+		diffLines = nil
+	}
+
+	closeResult := func() {
+		if openResult >= 0 {
+			finishHostResult(currentTask, openResult, payload)
+		}
+		openResult = -1
+		payload = nil
+	}
+	startResult := func(status, host, line string) {
+		currentTask.Status = status
+		currentTask.Host = host
+		openResult = currentTask.addHostResult(status, host, reader)
+		currentTask.Hosts[openResult].Diff = pendingDiff
+		pendingDiff = ""
+		if start := payloadStart(line); start != "" {
+			payload = []string{start}
+		}
+	}
+
+	for {
+		line, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		// Colour codes from ANSIBLE_FORCE_COLOR would defeat the anchored
+		// regexes below; the raw text keeps them for display
+		line = stripANSI(line)
 
 		// Check if we're entering a new task
 		if strings.HasPrefix(line, "TASK [") {
 			// If we have a current task, save it
 			if currentTask != nil {
+				closeResult()
+				finishTask(currentTask)
 				// Add any remaining diff content
-				if len(diffLines) > 0 {
-					if currentTask.Diff != "" {
-						currentTask.Diff += "\n" + strings.Join(diffLines, "\n")
-					} else {
-						currentTask.Diff = strings.Join(diffLines, "\n")
-					}
-				}
+				endDiff()
+				currentTask.EndOffset = reader.lineStart
+				currentTask.EndLine = reader.lineNo - 1
 				// Log the task before appending to tasks
-				debugLog.Printf("ParseTask() - Task ID: %d\nDescription: %s\nStatus: %s\nHost: %s\nPath: %s\nStartTime: %s\nDiff: %s\nRawText (first 100 chars): %s\n\n",
+				debugLog.Printf("ParseTask() - Task ID: %d\nDescription: %s\nStatus: %s\nHost: %s\nPath: %s\nStartTime: %s\nDiff: %s\nLines: %d-%d\nOffsets: %d-%d\n\n",
 					currentTask.ID, currentTask.Description, currentTask.Status, currentTask.Host,
 					currentTask.Path, currentTask.StartTime.Format("2006-01-02 15:04:05"),
-					currentTask.Diff,
-					func() string {
-						if len(currentTask.RawText) > 100 {
-							return currentTask.RawText[:100] + "..."
-						}
-						return currentTask.RawText
-					}())
+					currentTask.Diff, currentTask.StartLine, currentTask.EndLine,
+					currentTask.StartOffset, currentTask.EndOffset)
 
 				p.tasks = append(p.tasks, *currentTask)
 			}
 
-			// Reset diff lines for the new task
+			// Reset diff and verbose state for the new task
+			inDiffSection = false
 			diffLines = nil
+			pendingDiff = ""
+			verboseHost = ""
 
 			currentTask = &Task{
 				ID:          taskID,
-				Description: strings.TrimSpace(taskRegex.FindStringSubmatch(line)[1]),
-				Status:      "unknown",   // Default status
-				RawText:     line + "\n", // Start building raw text with the task header
+				Description: taskDescription(taskRegex, line),
+				Play:        play,
+				Status:      "unknown", // Default status
+				StartOffset: reader.lineStart,
+				StartLine:   reader.lineNo,
 			}
 			taskID++
 			continue
 		}
 
+		// Tasks belong to the play whose header was printed last
+		if matches := playRegex.FindStringSubmatch(line); matches != nil {
+			play = matches[1]
+		}
+
 		// If we don't have a current task, skip
 		if currentTask == nil {
 			continue
 		}
 
-		// Add the current line to the raw text
-		currentTask.RawText += line + "\n"
+		// User-defined rules see every line; one recording a host result
+		// starts a new result like a built-in status line
+		ruleResult := -1
+		for i := range p.rules {
+			if matched, result := p.rules[i].apply(currentTask, line, reader); matched && result >= 0 {
+				ruleResult = result
+			}
+		}
+		if ruleResult >= 0 {
+			closeResult()
+			openResult = ruleResult
+			continue
+		}
+
+		// Lines following a status line belong to that host result until a
+		// blank line or the next result (multi-line payloads, "...ignoring")
+		inResult := false
+		if openResult >= 0 {
+			if line == "" || diffBeforeRegex.MatchString(line) || statusLineRegex.MatchString(line) ||
+				verboseRegex.MatchString(line) || startedRegex.MatchString(line) || strings.HasPrefix(line, "PLAY ") ||
+				warningRegex.MatchString(line) || retryRegex.MatchString(line) {
+				closeResult()
+			} else {
+				result := &currentTask.Hosts[openResult]
+				result.EndLine = reader.lineNo
+				result.EndOffset = reader.offset
+				if payload != nil {
+					payload = append(payload, line)
+				}
+				inResult = true
+			}
+		}
+
+		if inResult {
+			continue
+		}
+
+		// Connection and module execution output of -vvv and above
+		if matches := verboseRegex.FindStringSubmatch(line); matches != nil {
+			verboseHost = matches[1]
+			currentTask.addEvent(matches[1], classifyVerbose(matches[2]), matches[2], reader.lineNo)
+			continue
+		}
+		if matches := moduleFileRegex.FindStringSubmatch(line); matches != nil {
+			currentTask.addEvent(verboseHost, EventModule, matches[1], reader.lineNo)
+			continue
+		}
 
 		// Check if we're entering a diff section
-		if diffStartRegex.MatchString(line) {
+		if diffBeforeRegex.MatchString(line) {
 			inDiffSection = true
 			diffLines = []string{line}
 			continue
 		}
 
-		// If we're in a diff section, collect lines until we hit a blank line or task separator
+		// If we're in a diff section, collect lines until we hit a blank line or status line
 		if inDiffSection {
-			// End of diff section when we hit a blank line, task separator, or status line
-			if line == "" || strings.HasPrefix(line, "TASK [") ||
-				strings.HasPrefix(line, "ok:") || strings.HasPrefix(line, "changed:") ||
-				strings.HasPrefix(line, "skipping:") || strings.HasPrefix(line, "failed:") || strings.HasPrefix(line, "fatal:") {
-				// Save the diff to the current task
-				if len(diffLines) > 0 {
-					if currentTask.Diff != "" {
-						currentTask.Diff += "\n" + strings.Join(diffLines, "\n")
-					} else {
-						currentTask.Diff = strings.Join(diffLines, "\n")
-					}
-				}
-				inDiffSection = false
-				diffLines = nil
-
-				// If this was a new task, we've already processed it above
-				if strings.HasPrefix(line, "TASK [") {
-					continue
-				}
-			} else {
+			if line != "" && !statusLineRegex.MatchString(line) {
 				diffLines = append(diffLines, line)
+				continue
+			}
+			endDiff()
+			// The status line reporting the diff's host is handled below
+			if line == "" {
+				continue
 			}
-			continue
 		}
 
 		// Extract task path
@@ -154,31 +421,34 @@ func (p *LogParser) ParseFile(filename string) ([]Task, error) {
 			continue
 		}
 
-		// Extract start time
-		if matches := timeRegex.FindStringSubmatch(line); len(matches) > 7 {
-			// Parse the time: Tuesday 28 October 2025  02:05:23
-			// weekday := matches[1]  // Not used, commented out
-			day := matches[2]
-			monthStr := matches[3]
-			year := matches[4]
-			hour := matches[5]
-			minute := matches[6]
-			second := matches[7]
-
-			// Convert month name to number
-			monthNum := monthMap[monthStr]
-			if monthNum == "" {
-				monthNum = "01" // Default to January
+		// Extract start time; timestamps that can't be interpreted are kept
+		// as text rather than replaced by a guessed date
+		if t, raw, ok := parseTimestampLine(line); ok {
+			// profile_tasks reports how long the previous task took; a
+			// second timestamp in a task follows its end (before the recap)
+			if elapsed, ok := profileElapsed(line); ok {
+				if currentTask.StartTimeRaw != "" {
+					currentTask.Duration = elapsed
+				} else if len(p.tasks) > 0 {
+					p.tasks[len(p.tasks)-1].Duration = elapsed
+				}
 			}
-
-			// Format: 2025-10-28 02:05:23
-			timeStr := fmt.Sprintf("%s-%s-%s %s:%s:%s", year, monthNum, day, hour, minute, second)
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
+			if currentTask.StartTimeRaw == "" {
 				currentTask.StartTime = t
+				currentTask.StartTimeRaw = raw
 			}
 			continue
 		}
 
+		if matches := warningRegex.FindStringSubmatch(line); matches != nil {
+			currentTask.addWarning(matches[1])
+			continue
+		}
+		if retryRegex.MatchString(line) {
+			currentTask.Retries++
+			continue
+		}
+
 		// Extract host from started line
 		if matches := startedRegex.FindStringSubmatch(line); len(matches) > 2 {
 			currentTask.Host = matches[2] // Set the host from the started line
@@ -187,64 +457,66 @@ func (p *LogParser) ParseFile(filename string) ([]Task, error) {
 
 		// Check for status updates
 		if matches := okRegex.FindStringSubmatch(line); len(matches) > 1 {
-			currentTask.Status = "ok"
-			currentTask.Host = matches[1]
+			startResult("ok", matches[1], line)
 			continue
 		}
 
 		if matches := changedRegex.FindStringSubmatch(line); len(matches) > 1 {
-			currentTask.Status = "changed"
-			currentTask.Host = matches[1]
+			startResult("changed", matches[1], line)
 			continue
 		}
 
 		if matches := skippingRegex.FindStringSubmatch(line); len(matches) > 1 {
-			currentTask.Status = "skipping"
-			currentTask.Host = matches[1]
+			startResult("skipping", matches[1], line)
 			continue
 		}
 
 		if matches := failedRegex.FindStringSubmatch(line); len(matches) > 1 {
-			currentTask.Status = "failed"
-			currentTask.Host = matches[1]
+			startResult("failed", matches[1], line)
 			continue
 		}
 
 		if matches := fatalRegex.FindStringSubmatch(line); len(matches) > 1 {
-			currentTask.Status = "fatal"
-			currentTask.Host = matches[1]
+			// Connection failures are reported as "fatal: [web01]: UNREACHABLE!"
+			if strings.Contains(line, "]: UNREACHABLE!") {
+				startResult("unreachable", matches[1], line)
+			} else {
+				startResult("fatal", matches[1], line)
+			}
 			continue
 		}
 	}
 
 	// Add the last task if it exists
 	if currentTask != nil {
+		closeResult()
+		finishTask(currentTask)
 		// Add any remaining diff content
-		if len(diffLines) > 0 {
-			if currentTask.Diff != "" {
-				currentTask.Diff += "\n" + strings.Join(diffLines, "\n")
-			} else {
-				currentTask.Diff = strings.Join(diffLines, "\n")
-			}
-		}
+		endDiff()
+		currentTask.EndOffset = reader.offset
+		currentTask.EndLine = reader.lineNo
 		// Log the last task
-		debugLog.Printf("ParseTask() - Task ID: %d\nDescription: %s\nStatus: %s\nHost: %s\nPath: %s\nStartTime: %s\nDiff: %s\nRawText (first 1000 chars): %s\n\n",
+		debugLog.Printf("ParseTask() - Task ID: %d\nDescription: %s\nStatus: %s\nHost: %s\nPath: %s\nStartTime: %s\nDiff: %s\nLines: %d-%d\nOffsets: %d-%d\n\n",
 			currentTask.ID, currentTask.Description, currentTask.Status, currentTask.Host,
 			currentTask.Path, currentTask.StartTime.Format("2006-01-02 15:04:05"),
-			currentTask.Diff,
-			func() string {
-				if len(currentTask.RawText) > 1000 {
-					return currentTask.RawText[:1000] + "..."
-				}
-				return currentTask.RawText
-			}())
+			currentTask.Diff, currentTask.StartLine, currentTask.EndLine,
+			currentTask.StartOffset, currentTask.EndOffset)
 
 		p.tasks = append(p.tasks, *currentTask)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %v", err)
-	}
+	return nil
+}
 
-	return p.tasks, nil
+// taskDescription extracts the task name from a "TASK [...]" header, falling
+// back to the whole bracketed text for headers without the trailing stars.
+func taskDescription(taskRegex *regexp.Regexp, line string) string {
+	if matches := taskRegex.FindStringSubmatch(line); matches != nil {
+		return strings.TrimSpace(matches[1])
+	}
+	name := strings.TrimPrefix(line, "TASK [")
+	if i := strings.LastIndex(name, "]"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name)
 }
@@ -0,0 +1,564 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLog writes content to a temporary log file and returns its path.
+func writeLog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ansible.log")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing log: %v", err)
+	}
+	return path
+}
+
+func TestParseFileSampleDemo(t *testing.T) {
+	tasks, err := NewLogParser(false).ParseFile("../../testdata/sample-demo.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 10 {
+		t.Fatalf("got %d tasks, want 10", len(tasks))
+	}
+	first := tasks[0]
+	if first.Description != "Gathering Facts" || first.Status != "ok" || first.Host != "web02.example.com" {
+		t.Errorf("unexpected first task: %+v", first)
+	}
+	if first.Path != "/home/user/playbooks/webserver.yml:2" {
+		t.Errorf("Path = %q", first.Path)
+	}
+	if got := first.StartTime.Format("2006-01-02 15:04:05"); got != "2025-10-28 14:20:32" {
+		t.Errorf("StartTime = %s", got)
+	}
+	if tasks[9].Status != "fatal" {
+		t.Errorf("last task status = %q, want fatal", tasks[9].Status)
+	}
+}
+
+func TestParseFileLongLine(t *testing.T) {
+	stdout := strings.Repeat("x", 5*1024*1024)
+	log := "TASK [Dump output] ***\n" +
+		`ok: [web01] => {"stdout": "` + stdout + `"}` + "\n" +
+		"\nTASK [Next] ***\nchanged: [web02]\n"
+
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	if tasks[0].Status != "ok" || tasks[0].Host != "web01" {
+		t.Errorf("unexpected first task: status=%q host=%q", tasks[0].Status, tasks[0].Host)
+	}
+	raw, err := tasks[0].LoadRawText()
+	if err != nil {
+		t.Fatalf("LoadRawText: %v", err)
+	}
+	if !strings.Contains(raw, stdout) {
+		t.Error("raw text lost the long line")
+	}
+	if tasks[1].Status != "changed" {
+		t.Errorf("second task status = %q, want changed", tasks[1].Status)
+	}
+}
+
+func TestParseFileRawTextOffsets(t *testing.T) {
+	log := "PLAY [all] ***\n\nTASK [First] ***\r\nok: [web01]\r\n\nTASK [Second] ***\nchanged: [web01]"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	want := []string{
+		"TASK [First] ***\r\nok: [web01]\r\n\n",
+		"TASK [Second] ***\nchanged: [web01]",
+	}
+	for i, task := range tasks {
+		if task.RawText != "" {
+			t.Errorf("task %d keeps a copy of its raw text", i)
+		}
+		raw, err := task.LoadRawText()
+		if err != nil {
+			t.Fatalf("LoadRawText: %v", err)
+		}
+		if raw != want[i] {
+			t.Errorf("task %d raw text = %q, want %q", i, raw, want[i])
+		}
+	}
+}
+
+func TestTruncateLongLines(t *testing.T) {
+	text := "short\n" + strings.Repeat("é", 20)
+	got := truncateLongLines(text, 11)
+	lines := strings.Split(got, "\n")
+	if lines[0] != "short" {
+		t.Errorf("short line changed: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], strings.Repeat("é", 5)+" … [+30 bytes") {
+		t.Errorf("unexpected truncation: %q", lines[1])
+	}
+}
+
+func TestParseFileIndexCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	path := writeLog(t, "TASK [First] ***\nok: [web01]\n\nTASK [Second] ***\n")
+
+	parse := func() []Task {
+		t.Helper()
+		parser := NewLogParser(false)
+		parser.SetIndexCacheDir(cacheDir)
+		t.Cleanup(func() { parser.Close() })
+		tasks, err := parser.ParseFile(path)
+		if err != nil {
+			t.Fatalf("ParseFile: %v", err)
+		}
+		return tasks
+	}
+
+	first := parse()
+	if _, err := os.Stat(indexFile(cacheDir, path)); err != nil {
+		t.Fatalf("no index written: %v", err)
+	}
+	cached := parse()
+	if len(cached) != len(first) || cached[1].StartOffset != first[1].StartOffset {
+		t.Fatalf("cached tasks differ: %+v vs %+v", cached, first)
+	}
+	if raw, _ := cached[0].LoadRawText(); raw != "TASK [First] ***\nok: [web01]\n\n" {
+		t.Errorf("cached task raw text = %q", raw)
+	}
+
+	// Appending resumes from the last indexed task, which must be completed
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("changed: [web02]\n\nTASK [Third] ***\nfatal: [web03]: FAILED!\n")
+	f.Close()
+
+	resumed := parse()
+	if len(resumed) != 3 {
+		t.Fatalf("got %d tasks after append, want 3", len(resumed))
+	}
+	if resumed[1].ID != 2 || resumed[1].Status != "changed" || resumed[1].Host != "web02" {
+		t.Errorf("unexpected resumed task: %+v", resumed[1])
+	}
+	if resumed[2].ID != 3 || resumed[2].Status != "fatal" {
+		t.Errorf("unexpected appended task: %+v", resumed[2])
+	}
+}
+
+func TestParseFileSourceLines(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-demo.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	first := tasks[0]
+	if first.StartLine != 3 || first.EndLine != 8 {
+		t.Errorf("first task lines = %d-%d, want 3-8", first.StartLine, first.EndLine)
+	}
+	if len(first.Hosts) != 2 || first.Hosts[1].Host != "web02.example.com" || first.Hosts[1].StartLine != 7 {
+		t.Errorf("unexpected host results: %+v", first.Hosts)
+	}
+
+	last := tasks[len(tasks)-1]
+	if len(last.Hosts) != 2 {
+		t.Fatalf("got %d host results, want 2", len(last.Hosts))
+	}
+	result := last.Hosts[0]
+	if result.Status != "fatal" || result.StartLine != 60 || result.EndLine != 61 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	text, err := parser.source.readRange(result.StartOffset, result.EndOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "fatal: [web01.example.com]") || !strings.HasSuffix(text, "...ignoring\n") {
+		t.Errorf("result offsets cover %q", text)
+	}
+}
+
+func TestParseTimestampLine(t *testing.T) {
+	cet := time.FixedZone("", 3600)
+	tests := []struct {
+		line string
+		want time.Time
+		ok   bool
+	}{
+		{"Tuesday 28 October 2025  02:05:23 +0100 (0:00:00.084)       0:00:00.084 ******", time.Date(2025, 10, 28, 2, 5, 23, 0, cet), true},
+		{"Dienstag 28. Oktober 2025  02:05:23 +0100 (0:00:00.084)", time.Date(2025, 10, 28, 2, 5, 23, 0, cet), true},
+		{"martedì 04 marzo 2025  14:20:32 +0100", time.Date(2025, 3, 4, 14, 20, 32, 0, cet), true},
+		{"Mittwoch 5 März 2025  14:20:32", time.Date(2025, 3, 5, 14, 20, 32, 0, time.UTC), true},
+		{"2025-10-28T02:05:23.5+01:00", time.Date(2025, 10, 28, 2, 5, 23, 500000000, cet), true},
+		{"2025-10-28 02:05:23,250 ", time.Date(2025, 10, 28, 2, 5, 23, 250000000, time.UTC), true},
+		{"2025-10-28T02:05:23Z", time.Date(2025, 10, 28, 2, 5, 23, 0, time.UTC), true},
+		{"Tuesday 28 Brumaire 2025  02:05:23 +0100", time.Time{}, true},
+		{"Tuesday 31 February 2025  02:05:23 +0100", time.Time{}, true},
+		{"ok: [web01]", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, raw, ok := parseTimestampLine(tt.line)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseTimestampLine(%q) = %v, %v; want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+		if ok && raw == "" {
+			t.Errorf("parseTimestampLine(%q) returned no raw text", tt.line)
+		}
+	}
+}
+
+func TestParseFileUnparsedTime(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, "TASK [First] ***\nTuesday 28 Brumaire 2025  02:05:23 +0100\nok: [web01]\n"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if !tasks[0].TimeUnparsed() || tasks[0].StartTimeRaw != "Tuesday 28 Brumaire 2025  02:05:23 +0100" {
+		t.Errorf("task not marked unparsed: %+v", tasks[0])
+	}
+}
+
+func TestParseFileRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	config := `{"rules": [
+		{"name": "audit", "pattern": "^AUDIT ticket=(?P<ticket>\\S+) approver=(?P<approver>\\S+)"},
+		{"pattern": "^(VERIFIED|REJECTED): \\[(.*?)\\]", "fields": {"status": "$1", "host": "$2"},
+		 "status_map": {"VERIFIED": "ok", "REJECTED": "failed"}, "attributes": {"verifier": "qa"}}
+	]}`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(configPath, true)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	parser := NewLogParser(false)
+	defer parser.Close()
+	parser.SetRules(cfg.Rules)
+	tasks, err := parser.ParseFile(writeLog(t, "TASK [Deploy] ***\nAUDIT ticket=CHG123 approver=alice\nok: [web01]\nREJECTED: [web02]\n"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	task := tasks[0]
+	want := map[string]string{"ticket": "CHG123", "approver": "alice", "verifier": "qa"}
+	for name, value := range want {
+		if task.Attributes[name] != value {
+			t.Errorf("attribute %s = %q, want %q", name, task.Attributes[name], value)
+		}
+	}
+	if task.Status != "failed" || task.Host != "web02" {
+		t.Errorf("status/host = %q/%q, want failed/web02", task.Status, task.Host)
+	}
+	if len(task.Hosts) != 2 || task.Hosts[1].Status != "failed" || task.Hosts[1].StartLine != 4 {
+		t.Errorf("unexpected host results: %+v", task.Hosts)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"), false); err != nil {
+		t.Errorf("optional missing config: %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"), true); err == nil {
+		t.Error("required missing config: no error")
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"rules": [{"pattern": "x", "fields": {"owner": "$1"}}]}`), 0o644)
+	if _, err := LoadConfig(path, false); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("unknown field: err = %v", err)
+	}
+}
+
+func TestParseFileForceColor(t *testing.T) {
+	log := "\x1b[0;34mTASK [Install nginx] ***\x1b[0m\n" +
+		"\x1b[0;32mok: [web01]\x1b[0m\n" +
+		"\x1b[0;33mchanged: [web02] => {\"changed\": true}\x1b[0m\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(tasks))
+	}
+	task := tasks[0]
+	if task.Description != "Install nginx" || task.Status != "changed" || len(task.Hosts) != 2 {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	if raw, _ := task.LoadRawText(); raw != log {
+		t.Errorf("raw text lost the colours: %q", raw)
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	in := "\x1b[0;31mfatal: [db01]\x1b[0m \x1b]8;;http://x\x07link\x1b]8;;\x07"
+	if got := stripANSI(in); got != "fatal: [db01] link" {
+		t.Errorf("stripANSI = %q", got)
+	}
+	colored := "\x1b[0;32m" + strings.Repeat("a", 20)
+	if got := truncateLongLines(colored, 4); !strings.HasPrefix(got, " … [+27 bytes") {
+		t.Errorf("truncation split an escape sequence: %q", got)
+	}
+	if got := truncateLongLines(colored, 10); !strings.HasPrefix(got, "\x1b[0;32maaa\x1b[0m …") {
+		t.Errorf("truncation split an escape sequence: %q", got)
+	}
+}
+
+func TestParseFileVerboseEvents(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-verbose.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+
+	var kinds []string
+	for _, event := range tasks[0].Events {
+		kinds = append(kinds, event.Kind)
+	}
+	want := "connection exec exec_result exec module transfer transfer become"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("event kinds = %q, want %q", got, want)
+	}
+	if event := tasks[0].Events[4]; event.Host != "web01" || !strings.HasSuffix(event.Detail, "/setup.py") || event.Line != 9 {
+		t.Errorf("unexpected module event: %+v", event)
+	}
+
+	events := tasks[1].Events
+	last := events[len(events)-1]
+	if last.Kind != EventModuleArgs || !strings.Contains(last.Detail, `"dest":"/etc/sudoers.d/deploy"`) || last.Line != 21 {
+		t.Errorf("unexpected module_args event: %+v", last)
+	}
+	if tasks[1].Status != "changed" || len(tasks[1].Hosts) != 1 {
+		t.Errorf("verbose lines confused the result: %+v", tasks[1])
+	}
+}
+
+func TestParseFileModuleArgs(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-verbose.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	result := tasks[1].Hosts[0]
+	if result.Module != "copy" || tasks[1].Module != "copy" {
+		t.Errorf("module = %q/%q, want copy", result.Module, tasks[1].Module)
+	}
+	if !strings.Contains(result.ModuleArgs, `"dest":"/etc/sudoers.d/deploy"`) {
+		t.Errorf("ModuleArgs = %s", result.ModuleArgs)
+	}
+	if !tasks[2].moduleMatches("ansible.builtin.command") || tasks[2].moduleMatches("copy") {
+		t.Errorf("unexpected module match for %q", tasks[2].Module)
+	}
+
+	unnamed, err := parser.ParseFile(writeLog(t, "TASK [nginx : ansible.builtin.template] ***\nok: [web01]\n"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if unnamed[0].Module != "ansible.builtin.template" || !unnamed[0].moduleMatches("template") {
+		t.Errorf("module of unnamed task = %q", unnamed[0].Module)
+	}
+
+	// One-word task names are only modules when Ansible knows them, and
+	// verbose output wins over the name
+	log := "TASK [nginx : install] ***\nok: [web01]\n\n" +
+		"TASK [nginx : copy] ***\n" +
+		"<web01> ESTABLISH SSH CONNECTION FOR USER: deploy\n" +
+		"Using module file /usr/lib/python3/site-packages/ansible/modules/template.py\n" +
+		"ok: [web01]\n\n" +
+		"TASK [ping] ***\nok: [web01]\n"
+	named, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if named[0].Module != "" || named[1].Module != "template" || named[2].Module != "ping" {
+		t.Errorf("modules = %q, %q, %q", named[0].Module, named[1].Module, named[2].Module)
+	}
+}
+
+func TestParseFileDebugMessages(t *testing.T) {
+	log := `TASK [Show release notes] ***
+ok: [web01] => {
+    "msg": [
+        "Release 1.2",
+        "  - fixed login"
+    ]
+}
+ok: [web02] => {
+    "msg": "first\nsecond"
+}
+
+TASK [Show version] ***
+ok: [web01] => {
+    "app_version": {
+        "major": 1
+    }
+}
+ok: [web02] => (item=a) => {
+    "ansible_loop_var": "item",
+    "item": "a",
+    "msg": "item a"
+}
+changed: [web03] => {"changed": true, "dest": "/tmp/x"}
+`
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	tests := []struct {
+		task, host int
+		want       []string
+	}{
+		{0, 0, []string{"Release 1.2", "  - fixed login"}},
+		{0, 1, []string{"first", "second"}},
+		{1, 0, []string{"app_version:", "  {", `    "major": 1`, "  }"}},
+		{1, 1, []string{"item a"}},
+		{1, 2, nil},
+	}
+	for _, tt := range tests {
+		got := tasks[tt.task].Hosts[tt.host].Messages
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("task %d host %d messages = %q, want %q", tt.task, tt.host, got, tt.want)
+		}
+	}
+
+	// Other modules returning a single value aren't debug output
+	for _, tt := range []struct {
+		res    map[string]any
+		module string
+		want   string
+	}{
+		{map[string]any{"changed": false, "ping": "pong"}, "", ""},
+		{map[string]any{"changed": false, "ping": "pong"}, "ping", ""},
+		{map[string]any{"ansible_facts": map[string]any{"x": 1}}, "set_fact", ""},
+		{map[string]any{"changed": false, "msg": "All assertions passed"}, "assert", "All assertions passed"},
+		{map[string]any{"app_version": "1.2"}, "", "app_version: 1.2"},
+		{map[string]any{"changed": false, "app_version": "1.2"}, "ansible.builtin.debug", "app_version: 1.2"},
+	} {
+		if got := strings.Join(resultMessages(tt.res, tt.module), "|"); got != tt.want {
+			t.Errorf("resultMessages(%v, %q) = %q, want %q", tt.res, tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestParseFileFailedCommand(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-verbose.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	command := tasks[2].Hosts[0].Command
+	if command == nil {
+		t.Fatal("no command result for failed task")
+	}
+	if command.Command != "visudo -c" || command.RC != 1 || command.Msg != "non-zero return code" ||
+		len(command.Stderr) != 2 || command.Stderr[1] != "parse error in /etc/sudoers.d/broken near line 3" {
+		t.Errorf("unexpected command result: %+v", command)
+	}
+	if tasks[1].Hosts[0].Command != nil {
+		t.Error("command result recorded for a successful task")
+	}
+
+	// Without -v the payload has neither invocation nor *_lines
+	log := "TASK [Run script] ***\n" +
+		`fatal: [web01]: FAILED! => {"changed": true, "cmd": "./deploy.sh", "rc": 2, "stderr": "", "stdout": "step 1\nstep 2\n"}` + "\n"
+	tasks, err = parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	command = tasks[0].Hosts[0].Command
+	if command == nil || command.Command != "./deploy.sh" || command.RC != 2 ||
+		strings.Join(command.Stdout, "|") != "step 1|step 2" || command.Stderr != nil {
+		t.Errorf("unexpected command result: %+v", command)
+	}
+	if !strings.Contains(renderFailures(&tasks[0]), "        2  step 2") {
+		t.Errorf("unexpected failure view:\n%s", renderFailures(&tasks[0]))
+	}
+}
+
+func TestParseFileCensored(t *testing.T) {
+	log := "TASK [Set password] ***\n" +
+		`changed: [web01] => {"censored": "the output has been hidden due to the fact that 'no_log: true' was specified for this result", "changed": true}` + "\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if result := tasks[0].Hosts[0]; !result.Censored || result.Messages != nil {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(renderSourceLines(&tasks[0]), "hidden by no_log") {
+		t.Errorf("censored result not marked:\n%s", renderSourceLines(&tasks[0]))
+	}
+}
+
+func TestParseFileDurationsAndRoles(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-demo.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if tasks[0].Duration != 1762*time.Millisecond || tasks[1].Duration != 5231*time.Millisecond {
+		t.Errorf("durations = %v, %v", tasks[0].Duration, tasks[1].Duration)
+	}
+
+	log := `TASK [nginx : Install nginx] ***
+Tuesday 28 October 2025  14:20:32 +0000 (0:00:00.084)       0:00:00.084 ******
+ok: [web01]
+
+TASK [Restart nginx] ***
+Tuesday 28 October 2025  14:20:40 +0000 (0:00:08.000)       0:00:08.084 ******
+ok: [web01]
+
+PLAY RECAP ***
+Tuesday 28 October 2025  14:20:42 +0000 (0:00:02.500)       0:00:10.584 ******
+`
+	tasks, err = parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if tasks[0].Role != "nginx" || tasks[1].Role != "" {
+		t.Errorf("roles = %q, %q", tasks[0].Role, tasks[1].Role)
+	}
+	// The timestamp after the last task gives its duration, not its start
+	if tasks[0].Duration != 8*time.Second || tasks[1].Duration != 2500*time.Millisecond ||
+		tasks[1].StartTime.Second() != 40 {
+		t.Errorf("unexpected last task: %+v", tasks[1])
+	}
+
+	// Without profile_tasks durations come from the next task's start
+	log = "TASK [a] ***\n2025-10-28T14:20:00Z\nTASK [b] ***\n2025-10-28T14:20:30Z\n"
+	tasks, err = parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if tasks[0].Duration != 30*time.Second || tasks[1].Duration != 0 {
+		t.Errorf("durations = %v, %v", tasks[0].Duration, tasks[1].Duration)
+	}
+}
@@ -0,0 +1,422 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// filterQuery is a compiled filter expression, see parseFilterQuery.
+type filterQuery interface {
+	match(task *Task) bool
+}
+
+type (
+	queryAnd  []filterQuery
+	queryOr   []filterQuery
+	queryNot  struct{ query filterQuery }
+	queryTerm func(task *Task) bool
+)
+
+func (q queryAnd) match(task *Task) bool {
+	for _, sub := range q {
+		if !sub.match(task) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q queryOr) match(task *Task) bool {
+	for _, sub := range q {
+		if sub.match(task) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q queryNot) match(task *Task) bool { return !q.query.match(task) }
+
+func (q queryTerm) match(task *Task) bool { return q(task) }
+
+// queryFieldRegex splits a term into a field qualifier, an operator and a
+// value, e.g. "status:failed" or "duration>30s".
+var queryFieldRegex = regexp.MustCompile(`^([a-z][a-z0-9_]*)(:|>=|<=|>|<|=)(.*)$`)
+
+// queryTimeLayouts are the accepted values of after: and before:. Values
+// without a date compare the time of day.
+var queryTimeLayouts = []string{
+	"15:04", "15:04:05",
+	"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339,
+}
+
+// queryToken is a word or parenthesis of a filter query.
+type queryToken struct {
+	text   string
+	quoted bool // The whole token was quoted, so it's never a keyword or field
+}
+
+// tokenizeQuery splits a filter query into words and parentheses. Double
+// quotes group words containing spaces, in which \" is a quote and \\ a
+// backslash; "-(" negates a group.
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case r == ' ' || r == '\t':
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{text: string(r)})
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '(':
+			tokens = append(tokens, queryToken{text: "NOT"})
+			i++
+		default:
+			var b strings.Builder
+			token := queryToken{quoted: r == '"'}
+			inQuotes := false
+			for ; i < len(runes); i++ {
+				r := runes[i]
+				if r == '"' {
+					inQuotes = !inQuotes
+					continue
+				}
+				if inQuotes && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+					r = runes[i]
+				}
+				if !inQuotes && (r == ' ' || r == '\t' || r == '(' || r == ')') {
+					break
+				}
+				b.WriteRune(r)
+			}
+			if inQuotes {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+			token.text = b.String()
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser is a recursive descent parser for filter queries.
+type queryParser struct {
+	tokens     []queryToken
+	pos        int
+	attributes map[string]bool // Names of custom attributes, see parseFilterQuery
+}
+
+// parseFilterQuery compiles a filter query. Terms separated by spaces must
+// all match; OR, NOT (or a "-" prefix) and parentheses combine them. A term
+// is either text searched in all task fields or a qualified term:
+//
+//	status:failed host:web* role:nginx path:roles/db name:"Install nginx"
+//	play:deploy module:template warning:deprecated after:14:20
+//	before:2025-10-28T15:00 duration>30s duration<=1m
+//
+// Values of text qualifiers match case-insensitively as substrings, or as a
+// whole when they contain the wildcards "*" or "?"; "warning:*" matches all
+// tasks with warnings. A value starting with "=" matches exactly, so
+// "host:=web1" leaves out web10.
+//
+// Custom attributes set by line rules qualify terms like fields, e.g.
+// "ticket:CHG1", if their lower-case name is in attributes.
+func parseFilterQuery(input string, attributes map[string]bool) (filterQuery, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, attributes: attributes}
+	if len(tokens) == 0 {
+		return queryAnd{}, nil
+	}
+	query, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return query, nil
+}
+
+// keyword reports whether the next token is the unquoted keyword word.
+func (p *queryParser) keyword(word string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == word
+}
+
+func (p *queryParser) parseOr() (filterQuery, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := queryOr{left}
+	for p.keyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, right)
+	}
+	if len(or) == 1 {
+		return left, nil
+	}
+	return or, nil
+}
+
+func (p *queryParser) parseAnd() (filterQuery, error) {
+	var and queryAnd
+	for p.pos < len(p.tokens) && !p.keyword(")") && !p.keyword("OR") {
+		if p.keyword("AND") {
+			p.pos++
+			continue
+		}
+		query, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, query)
+	}
+	switch len(and) {
+	case 0:
+		if p.pos < len(p.tokens) {
+			return nil, fmt.Errorf("missing term before %q", p.tokens[p.pos].text)
+		}
+		return nil, fmt.Errorf("missing term at end of query")
+	case 1:
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *queryParser) parseUnary() (filterQuery, error) {
+	if p.keyword("NOT") {
+		p.pos++
+		if p.pos == len(p.tokens) {
+			return nil, fmt.Errorf("missing term after NOT")
+		}
+		query, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{query}, nil
+	}
+	if p.keyword("(") {
+		p.pos++
+		query, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return query, nil
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	if !token.quoted && len(token.text) > 1 && strings.HasPrefix(token.text, "-") {
+		query, err := compileQueryTerm(queryToken{text: token.text[1:]}, p.attributes)
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{query}, nil
+	}
+	return compileQueryTerm(token, p.attributes)
+}
+
+// compileQueryTerm compiles a single, possibly qualified, term. Qualifiers
+// other than the fields are the names of custom attributes.
+func compileQueryTerm(token queryToken, attributes map[string]bool) (filterQuery, error) {
+	m := queryFieldRegex.FindStringSubmatch(token.text)
+	if token.quoted || m == nil {
+		return textTerm(strings.ToLower(token.text)), nil
+	}
+	field, op, value := m[1], m[2], m[3]
+	if field == "duration" {
+		// Also accept "duration:>30s"
+		if op == ":" {
+			if rest := queryFieldRegex.FindStringSubmatch(field + value); rest != nil && rest[2] != ":" {
+				op, value = rest[2], rest[3]
+			}
+		}
+		return durationTerm(op, value)
+	}
+	if op != ":" {
+		// Not a qualifier, e.g. an attribute given as "ticket=CHG123"
+		return textTerm(strings.ToLower(token.text)), nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s:", field)
+	}
+
+	switch field {
+	case "after", "before":
+		return timeTerm(field, value)
+	case "module":
+		name := strings.ToLower(value)
+		return queryTerm(func(task *Task) bool { return task.moduleMatches(name) }), nil
+	}
+	matches := valueMatcher(strings.ToLower(value))
+	switch field {
+	case "status":
+		failed := strings.TrimPrefix(strings.ToLower(value), "=") == "failed"
+		return queryTerm(func(task *Task) bool {
+			statusMatches := func(status string) bool {
+				return matches(status) || failed && status == "fatal"
+			}
+			if statusMatches(task.Status) {
+				return true
+			}
+			for _, result := range task.Hosts {
+				if statusMatches(result.Status) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "host":
+		return queryTerm(func(task *Task) bool {
+			if matches(task.Host) {
+				return true
+			}
+			for _, result := range task.Hosts {
+				if matches(result.Host) || matches(inventoryHost(result.Host)) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case "role":
+		return queryTerm(func(task *Task) bool { return matches(task.Role) }), nil
+	case "path":
+		return queryTerm(func(task *Task) bool { return matches(task.Path) }), nil
+	case "name":
+		return queryTerm(func(task *Task) bool { return matches(task.Description) }), nil
+	case "play":
+		return queryTerm(func(task *Task) bool { return matches(task.Play) }), nil
+	case "warning":
+		return queryTerm(func(task *Task) bool { return slices.ContainsFunc(task.Warnings, matches) }), nil
+	}
+	if !attributes[field] {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	return queryTerm(func(task *Task) bool {
+		for name, value := range task.Attributes {
+			if strings.EqualFold(name, field) && matches(value) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// attributeNames returns the lower-case names of the custom attributes of
+// tasks, the qualifiers they add to queries.
+func attributeNames(tasks []*Task) map[string]bool {
+	names := make(map[string]bool)
+	for _, task := range tasks {
+		for name := range task.Attributes {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return names
+}
+
+// valueMatcher returns a case-insensitive matcher for the lower-case value
+// of a qualified term: a substring match, a whole match for a value with
+// wildcards, or an exact match for a value starting with "=".
+func valueMatcher(value string) func(string) bool {
+	if exact, ok := strings.CutPrefix(value, "="); ok {
+		return func(s string) bool { return strings.ToLower(s) == exact }
+	}
+	if !strings.ContainsAny(value, "*?") {
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), value) }
+	}
+	pattern := regexp.QuoteMeta(value)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	regex := regexp.MustCompile("^" + pattern + "$")
+	return func(s string) bool { return regex.MatchString(strings.ToLower(s)) }
+}
+
+// textTerm matches the lower-case term against all fields of a task, as the
+// filter did before it understood queries.
+func textTerm(term string) filterQuery {
+	return queryTerm(func(task *Task) bool {
+		return strings.Contains(strings.ToLower(task.Description), term) ||
+			strings.Contains(strings.ToLower(task.Status), term) ||
+			strings.Contains(strings.ToLower(task.Host), term) ||
+			strings.Contains(strings.ToLower(task.Path), term) ||
+			strings.Contains(strings.ToLower(task.Diff), term) ||
+			strings.Contains(task.StartTime.Format("2006-01-02 15:04:05"), term) ||
+			matchAttributes(task, term) ||
+			matchModuleArgs(task, term)
+	})
+}
+
+// durationTerm compiles "duration<op><value>". Tasks of unknown duration
+// never match.
+func durationTerm(op, value string) (filterQuery, error) {
+	limit, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q", value)
+	}
+	compare := map[string]func(time.Duration) bool{
+		">":  func(d time.Duration) bool { return d > limit },
+		">=": func(d time.Duration) bool { return d >= limit },
+		"<":  func(d time.Duration) bool { return d < limit },
+		"<=": func(d time.Duration) bool { return d <= limit },
+		"=":  func(d time.Duration) bool { return d == limit },
+	}[op]
+	if compare == nil {
+		return nil, fmt.Errorf("use duration>, duration< or duration= with a duration like 30s")
+	}
+	return queryTerm(func(task *Task) bool { return task.Duration > 0 && compare(task.Duration) }), nil
+}
+
+// timeTerm compiles "after:<time>" and "before:<time>". Tasks without a
+// start time never match.
+func timeTerm(field, value string) (filterQuery, error) {
+	for i, layout := range queryTimeLayouts {
+		limit, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		clockOnly := i < 2
+		return queryTerm(func(task *Task) bool {
+			start := task.StartTime
+			if start.IsZero() {
+				return false
+			}
+			var after bool
+			if clockOnly {
+				clock := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute +
+					time.Duration(start.Second())*time.Second
+				limitClock := time.Duration(limit.Hour())*time.Hour + time.Duration(limit.Minute())*time.Minute +
+					time.Duration(limit.Second())*time.Second
+				after = clock >= limitClock
+			} else {
+				// Dates without a zone are taken in the zone of the log
+				at := limit
+				if layout != time.RFC3339 {
+					at = time.Date(limit.Year(), limit.Month(), limit.Day(), limit.Hour(), limit.Minute(),
+						limit.Second(), 0, start.Location())
+				}
+				after = !start.Before(at)
+			}
+			if field == "after" {
+				return after
+			}
+			return !after
+		}), nil
+	}
+	return nil, fmt.Errorf("invalid time %q for %s: (use 15:04 or 2006-01-02T15:04)", value, field)
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseFilterQuery(t *testing.T) {
+	start := time.Date(2025, 10, 28, 14, 20, 0, 0, time.UTC)
+	tasks := []Task{
+		{Description: "nginx : Install nginx", Role: "nginx", Status: "changed", Host: "web01", Path: "roles/nginx/tasks/main.yml:3",
+			StartTime: start, Duration: 45 * time.Second, Hosts: []HostResult{{Host: "web01", Status: "changed"}, {Host: "web02", Status: "skipping"}}},
+		{Description: "db : Create schema", Role: "db", Status: "fatal", Host: "db03", Path: "roles/db/tasks/main.yml:9",
+			StartTime: start.Add(30 * time.Minute), Duration: 2 * time.Second, Module: "command", Hosts: []HostResult{{Host: "db03", Status: "fatal"}}},
+		{Description: "Gathering Facts", Status: "ok", Host: "web02", StartTime: start.Add(-time.Hour),
+			Attributes: map[string]string{"ticket": "CHG123"}, Hosts: []HostResult{{Host: "web02", Status: "ok"}}},
+	}
+	tests := []struct {
+		query string
+		want  string // Indexes of the matching tasks
+	}{
+		{"", "012"},
+		{"nginx", "0"},
+		{"status:failed", "1"},
+		{"-status:skipping", "12"},
+		{"host:web*", "02"},
+		{"host:web0?", "02"},
+		{"host:web", "02"},
+		{"host:=web0", ""},
+		{"host:=WEB01", "0"},
+		{"role:=ngin", ""},
+		{"status:=failed", "1"},
+		{"role:db OR role:nginx", "01"},
+		{"(role:db OR role:nginx) -status:fatal", "0"},
+		{"-(role:db OR role:nginx)", "2"},
+		{"NOT path:roles/db AND status:ok", "2"},
+		{"after:14:20 before:15:00", "01"},
+		{"after:2025-10-28T14:30", "1"},
+		{"duration>30s", "0"},
+		{"duration:<=2s", "1"},
+		{"module:command", "1"},
+		{"ticket=chg123", "2"},
+		{"ticket:CHG1", "2"},
+		{"-ticket:=chg1", "012"},
+		{`name:"create schema"`, "1"},
+		{`"role:db"`, ""},
+		{"14:20:00", "0"},
+	}
+	taskPointers := []*Task{&tasks[0], &tasks[1], &tasks[2]}
+	attributes := attributeNames(taskPointers)
+	for _, tt := range tests {
+		query, err := parseFilterQuery(tt.query, attributes)
+		if err != nil {
+			t.Errorf("parseFilterQuery(%q): %v", tt.query, err)
+			continue
+		}
+		got := ""
+		for i := range tasks {
+			if query.match(&tasks[i]) {
+				got += string(rune('0' + i))
+			}
+		}
+		if got != tt.want {
+			t.Errorf("query %q matched %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	tokens, err := tokenizeQuery(`name:"say \"hi\" \\o/" -x`)
+	if err != nil || len(tokens) != 2 || tokens[0].text != `name:say "hi" \o/` {
+		t.Errorf("tokenizeQuery = %+v, %v", tokens, err)
+	}
+
+	for _, query := range []string{"stauts:failed", "tikcet:chg1", "status:", "(role:db", "role:db)", "role:db OR", "duration>soon",
+		"after:noon", `name:"open`, "NOT"} {
+		if _, err := parseFilterQuery(query, attributes); err == nil {
+			t.Errorf("parseFilterQuery(%q): expected an error", query)
+		}
+	}
+}
+
+func TestFilterPlayOfTextLog(t *testing.T) {
+	log := "PLAY [Prepare hosts] ***\n\nTASK [Gathering Facts] ***\nok: [web1]\n\n" +
+		"PLAY [Deploy app] ***\n\nTASK [Gathering Facts] ***\nok: [web1]\n\nTASK [Copy release] ***\nchanged: [web1]\n\n" +
+		"PLAY RECAP ***\nweb1 : ok=3 changed=1\n"
+	path := writeLog(t, log)
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(tasks) != 3 || tasks[0].Play != "Prepare hosts" || tasks[2].Play != "Deploy app" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	// Parsing resumed at the last task keeps its play
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("\n")
+	file.Close()
+	if tasks, _, err := parser.Follow(); err != nil || tasks[2].Play != "Deploy app" {
+		t.Errorf("followed tasks = %+v, %v", tasks, err)
+	}
+
+	m := NewModel(tasks, false)
+	m.filterNodes("play:deploy")
+	if len(m.filteredNodes) != 2 || m.filteredNodes[0].Task.ID != 2 || m.filterErr != nil {
+		t.Errorf("play:deploy shows %+v, %v", m.filteredNodes, m.filterErr)
+	}
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// moduleNameRegex matches task names that are a fully qualified module
+// name, as Ansible prints for unnamed tasks ("ansible.builtin.template").
+var moduleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*\.[a-z][a-z0-9_]*\.[a-z][a-z0-9_]*$`)
+
+// knownModules are modules Ansible prints by their short name for unnamed
+// tasks. Other one-word task names, such as "nginx : install", aren't taken
+// for module names.
+var knownModules = map[string]bool{
+	"add_host": true, "apt": true, "apt_key": true, "apt_repository": true, "assemble": true, "assert": true,
+	"async_status": true, "blockinfile": true, "command": true, "copy": true, "cron": true, "debconf": true,
+	"debug": true, "dnf": true, "expect": true, "fail": true, "fetch": true, "file": true, "find": true,
+	"gather_facts": true, "get_url": true, "getent": true, "git": true, "group": true, "group_by": true,
+	"hostname": true, "import_role": true, "import_tasks": true, "include_role": true, "include_tasks": true,
+	"include_vars": true, "iptables": true, "known_hosts": true, "lineinfile": true, "meta": true,
+	"mount": true, "package": true, "package_facts": true, "pause": true, "ping": true, "pip": true,
+	"raw": true, "reboot": true, "replace": true, "rpm_key": true, "script": true, "service": true,
+	"service_facts": true, "set_fact": true, "set_stats": true, "setup": true, "shell": true, "slurp": true,
+	"stat": true, "synchronize": true, "systemd": true, "systemd_service": true, "sysvinit": true,
+	"template": true, "unarchive": true, "uri": true, "user": true, "wait_for": true,
+	"wait_for_connection": true, "yum": true, "yum_repository": true,
+}
+
+// payloadStart returns the JSON payload following "=>" on a host result line,
+// or "" if the line has none.
+func payloadStart(line string) string {
+	i := strings.Index(line, "=> ")
+	if i < 0 {
+		return ""
+	}
+	payload := line[i+len("=> "):]
+	// Loop results name the item first: "ok: [web01] => (item=x) => {"
+	if strings.HasPrefix(payload, "(item=") {
+		if i := strings.Index(payload, ") => "); i >= 0 {
+			return payload[i+len(") => "):]
+		}
+	}
+	return payload
+}
+
+// decodePayload decodes the JSON object at the start of text, ignoring any
+// text after it (such as "...ignoring"). It returns nil if text doesn't start
+// with a JSON object.
+func decodePayload(text string) map[string]any {
+	var res map[string]any
+	if err := json.NewDecoder(strings.NewReader(text)).Decode(&res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// finishHostResult extracts structured data from the payload lines of the
+// host result at index in task.Hosts once all its lines have been read.
+// Payloads are only decoded when they contain something of interest, since
+// decoding every result of a huge log would be slow. Multi-line payloads are
+// always decoded, as Ansible pretty-prints debug output, and so are failures
+// that report a return code.
+func finishHostResult(task *Task, index int, payload []string) {
+	result := &task.Hosts[index]
+	// The module file used last on the host produced this result
+	for i := len(task.Events) - 1; i >= 0; i-- {
+		if event := task.Events[i]; event.Kind == EventModule && event.Host == result.Host {
+			result.Module = strings.TrimSuffix(path.Base(event.Detail), ".py")
+			break
+		}
+	}
+
+	if len(payload) == 0 {
+		return
+	}
+	text := strings.Join(payload, "\n")
+	failedCommand := (result.Status == "failed" || result.Status == "fatal") && strings.Contains(text, `"rc"`)
+	if len(payload) == 1 && !failedCommand && !strings.Contains(text, `"invocation"`) && !strings.Contains(text, `"censored"`) &&
+		!strings.Contains(text, `"warnings"`) {
+		return
+	}
+	if res := decodePayload(text); res != nil {
+		applyResultPayload(task, index, res)
+	}
+}
+
+// applyResultPayload copies structured data from the decoded result payload
+// res (the module's return values) into the host result at index in
+// task.Hosts.
+func applyResultPayload(task *Task, index int, res map[string]any) {
+	result := &task.Hosts[index]
+	// no_log results only tell that their output was hidden
+	if _, ok := res["censored"]; ok {
+		result.Censored = true
+		return
+	}
+	if invocation, ok := res["invocation"].(map[string]any); ok {
+		if args, ok := invocation["module_args"]; ok {
+			data, _ := json.Marshal(args)
+			result.ModuleArgs = string(data)
+			task.addEvent(result.Host, EventModuleArgs, result.ModuleArgs, result.StartLine)
+		}
+	}
+	module := result.Module
+	if module == "" {
+		module = task.Module
+	}
+	result.Messages = resultMessages(res, module)
+	if warnings, ok := res["warnings"].([]any); ok {
+		for _, warning := range warnings {
+			task.addWarning(fmt.Sprint(warning))
+		}
+	}
+	// Text logs print the diff before the result, job events only have it
+	// in the result
+	if result.Diff == "" {
+		if diff := resultDiff(res); diff != "" {
+			result.Diff = diff
+			if task.Diff != "" {
+				task.Diff += "\n"
+			}
+			task.Diff += diff
+		}
+	}
+	if result.Status == "failed" || result.Status == "fatal" {
+		result.Command = commandResult(res)
+	}
+}
+
+// commandResult extracts the command, return code and output from the result
+// of a command, shell or script module, or returns nil if res has no return
+// code.
+func commandResult(res map[string]any) *CommandResult {
+	rc, ok := res["rc"].(float64)
+	if !ok {
+		return nil
+	}
+	command := &CommandResult{RC: int(rc)}
+	switch cmd := res["cmd"].(type) {
+	case string:
+		command.Command = cmd
+	case []any:
+		args := make([]string, len(cmd))
+		for i, arg := range cmd {
+			args[i] = fmt.Sprint(arg)
+		}
+		command.Command = strings.Join(args, " ")
+	}
+	command.Msg, _ = res["msg"].(string)
+	command.Stdout = outputLines(res, "stdout")
+	command.Stderr = outputLines(res, "stderr")
+	return command
+}
+
+// outputLines returns the lines of the output stream name of a result,
+// preferring the "<name>_lines" list over splitting the output itself.
+func outputLines(res map[string]any, name string) []string {
+	if list, ok := res[name+"_lines"].([]any); ok {
+		lines := make([]string, len(list))
+		for i, line := range list {
+			lines[i] = fmt.Sprint(line)
+		}
+		return lines
+	}
+	text, _ := res[name].(string)
+	text = strings.TrimRight(text, "\r\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// resultMessages returns the lines of a result's message: the "msg" of
+// debug's msg form and of modules like fail, or the single variable of
+// debug's var form. module is the module that returned res, if known; when it
+// isn't, only results without "changed" are taken for the var form, since
+// Ansible strips it from debug results alone. Other results return nil.
+func resultMessages(res map[string]any, module string) []string {
+	var name string
+	var value any
+	for key, v := range res {
+		switch {
+		case strings.HasPrefix(key, "_ansible_"), key == "changed", key == "failed", key == "skipped",
+			key == "item", key == "ansible_loop_var", key == "invocation":
+			continue
+		case name != "":
+			return nil // More than a message, so not a debug result
+		}
+		name, value = key, v
+	}
+	_, changed := res["changed"]
+	isDebug := module == "debug" || strings.HasSuffix(module, ".debug") || (module == "" && !changed)
+	switch {
+	case name == "":
+		return nil
+	case name == "msg":
+		return messageLines(value)
+	case !isDebug:
+		return nil
+	}
+	if s, ok := value.(string); ok && !strings.Contains(s, "\n") {
+		return []string{name + ": " + s}
+	}
+	lines := []string{name + ":"}
+	for _, line := range messageLines(value) {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
+
+// messageLines renders a debug message as text lines: strings are split at
+// their newlines, arrays contribute one or more lines per element and
+// anything else is shown as indented JSON.
+func messageLines(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(strings.TrimRight(v, "\n"), "\n")
+	case []any:
+		var lines []string
+		for _, element := range v {
+			if s, ok := element.(string); ok {
+				lines = append(lines, strings.Split(s, "\n")...)
+				continue
+			}
+			data, _ := json.Marshal(element)
+			lines = append(lines, string(data))
+		}
+		return lines
+	}
+	data, _ := json.MarshalIndent(value, "", "  ")
+	return strings.Split(string(data), "\n")
+}
+
+// finishTask fills in task fields derived from all of its lines once the task
+// has been read completely.
+func finishTask(task *Task) {
+	// Tasks of roles are named "role : task"
+	if i := strings.Index(task.Description, " : "); i >= 0 && task.Role == "" {
+		task.Role = task.Description[:i]
+	}
+
+	// The module file from verbose output names the module for certain
+	for _, result := range task.Hosts {
+		if result.Module != "" {
+			task.Module = result.Module
+			return
+		}
+	}
+
+	// Unnamed tasks are shown by their module name, possibly behind the role
+	name := task.Description
+	if i := strings.LastIndex(name, " : "); i >= 0 {
+		name = name[i+len(" : "):]
+	}
+	if moduleNameRegex.MatchString(name) || knownModules[name] {
+		task.Module = name
+	}
+}
+
+// moduleMatches reports whether the task ran the module name, given either
+// fully qualified ("ansible.builtin.copy") or short ("copy").
+func (t *Task) moduleMatches(name string) bool {
+	matches := func(module string) bool {
+		return module != "" && (module == name || strings.HasSuffix(module, "."+name) || strings.HasSuffix(name, "."+module))
+	}
+	if matches(t.Module) {
+		return true
+	}
+	for _, result := range t.Hosts {
+		if matches(result.Module) {
+			return true
+		}
+	}
+	return false
+}
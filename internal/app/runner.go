@@ -0,0 +1,224 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jobEvent is a playbook event as written by ansible-runner to
+// artifacts/<ident>/job_events/*.json. AWX and Ansible Automation Platform
+// use the same events.
+type jobEvent struct {
+	UUID       string       `json:"uuid"`
+	ParentUUID string       `json:"parent_uuid"`
+	Counter    int          `json:"counter"`
+	Event      string       `json:"event"`
+	Stdout     string       `json:"stdout"`
+	Created    string       `json:"created"`
+	EventData  jobEventData `json:"event_data"`
+}
+
+type jobEventData struct {
+	Host       string          `json:"host"`
+	Task       string          `json:"task"`
+	TaskUUID   string          `json:"task_uuid"`
+	TaskAction string          `json:"task_action"`
+	TaskPath   string          `json:"task_path"`
+	Play       string          `json:"play"`
+	Role       string          `json:"role"`
+	Res        json.RawMessage `json:"res"`
+	Start      string          `json:"start"`
+	End        string          `json:"end"`
+	Duration   float64         `json:"duration"` // Seconds
+}
+
+// eventStatuses maps host result events to task statuses; runner_on_ok
+// becomes "changed" when the result says so.
+var eventStatuses = map[string]string{
+	"runner_on_ok":           "ok",
+	"runner_on_async_ok":     "ok",
+	"runner_on_failed":       "fatal",
+	"runner_on_async_failed": "fatal",
+	"runner_on_skipped":      "skipping",
+	"runner_on_unreachable":  "unreachable",
+}
+
+// IsRunnerArtifactDir reports whether path is an ansible-runner artifact
+// directory (artifacts/<ident>) or its job_events directory.
+func IsRunnerArtifactDir(path string) bool {
+	if info, err := os.Stat(filepath.Join(path, "job_events")); err == nil && info.IsDir() {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir() && filepath.Base(filepath.Clean(path)) == "job_events"
+}
+
+// ReadRunnerArtifacts reads the job events of an ansible-runner artifact
+// directory and turns them into tasks with per-host results and timings.
+func ReadRunnerArtifacts(path string) ([]Task, error) {
+	dir := filepath.Join(path, "job_events")
+	if filepath.Base(filepath.Clean(path)) == "job_events" {
+		dir = path
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []jobEvent
+	for _, file := range files {
+		// Events still being written by a running job are skipped
+		if strings.Contains(filepath.Base(file), "partial") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading event: %v", err)
+		}
+		var event jobEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("error parsing event %s: %v", file, err)
+		}
+		events = append(events, event)
+	}
+	debugLog.Printf("ReadRunnerArtifacts() - Read %d events from %s", len(events), dir)
+	return buildTasksFromEvents(events), nil
+}
+
+// parseEventTime parses the ISO 8601 timestamps of job events, which may lack
+// a zone (UTC is assumed then).
+func parseEventTime(value string) time.Time {
+	t, _, _ := parseTimestampLine(value)
+	return t
+}
+
+// buildTasksFromEvents turns job events into tasks, in event counter order.
+func buildTasksFromEvents(events []jobEvent) []Task {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Counter < events[j].Counter })
+
+	var tasks []Task
+	var rawTexts []*strings.Builder
+	taskIndex := make(map[string]int) // task_uuid -> index in tasks
+	current := -1                     // Index of the task started last
+
+	// taskFor returns the index of the task an event belongs to, creating it
+	// from the event data if its start event is missing
+	taskFor := func(event jobEvent) int {
+		data := event.EventData
+		if i, ok := taskIndex[data.TaskUUID]; ok && data.TaskUUID != "" {
+			return i
+		}
+		name := data.Task
+		if data.Role != "" {
+			name = data.Role + " : " + name
+		}
+		tasks = append(tasks, Task{
+			ID:          len(tasks) + 1,
+			Description: name,
+			StartTime:   parseEventTime(event.Created),
+			Status:      "unknown",
+			Path:        data.TaskPath,
+			Module:      data.TaskAction,
+			Play:        data.Play,
+			Role:        data.Role,
+		})
+		rawTexts = append(rawTexts, &strings.Builder{})
+		taskIndex[data.TaskUUID] = len(tasks) - 1
+		return len(tasks) - 1
+	}
+
+	for _, event := range events {
+		i := -1
+		switch {
+		case event.Event == "playbook_on_task_start" || event.Event == "playbook_on_handler_task_start":
+			i = taskFor(event)
+			current = i
+		case eventStatuses[event.Event] != "":
+			i = taskFor(event)
+			addEventResult(&tasks[i], event)
+		case event.Event == "runner_retry":
+			i = taskFor(event)
+			tasks[i].Retries++
+		case (event.Event == "verbose" || event.Event == "warning") && current >= 0:
+			i = current
+			for _, line := range strings.Split(stripANSI(event.Stdout), "\n") {
+				line = strings.TrimSuffix(line, "\r")
+				if matches := verboseRegex.FindStringSubmatch(line); matches != nil {
+					tasks[i].addEvent(matches[1], classifyVerbose(matches[2]), matches[2], 0)
+				}
+				if matches := warningRegex.FindStringSubmatch(line); matches != nil {
+					tasks[i].addWarning(matches[1])
+				}
+			}
+		}
+		if i >= 0 && event.Stdout != "" {
+			stdout := strings.ReplaceAll(event.Stdout, "\r\n", "\n")
+			rawTexts[i].WriteString(strings.TrimPrefix(stdout, "\n") + "\n")
+		}
+	}
+
+	for i := range tasks {
+		tasks[i].RawText = rawTexts[i].String()
+		finishEventTask(&tasks[i])
+	}
+	return tasks
+}
+
+// addEventResult records the host result reported by a runner_on_* event.
+func addEventResult(task *Task, event jobEvent) {
+	data := event.EventData
+	status := eventStatuses[event.Event]
+
+	var res map[string]any
+	if len(data.Res) > 0 {
+		json.Unmarshal(data.Res, &res)
+	}
+	if changed, _ := res["changed"].(bool); changed && status == "ok" {
+		status = "changed"
+	}
+
+	start := parseEventTime(data.Start)
+	if start.IsZero() {
+		start = parseEventTime(event.Created)
+	}
+	duration := time.Duration(data.Duration * float64(time.Second))
+	if end := parseEventTime(data.End); duration == 0 && !end.IsZero() && !start.IsZero() {
+		duration = end.Sub(start)
+	}
+
+	task.Status = status
+	task.Host = data.Host
+	task.Hosts = append(task.Hosts, HostResult{
+		Host:      data.Host,
+		Status:    status,
+		Module:    data.TaskAction,
+		Result:    string(data.Res),
+		StartTime: start,
+		Duration:  duration,
+	})
+	if res != nil {
+		applyResultPayload(task, len(task.Hosts)-1, res)
+	}
+}
+
+// finishEventTask derives the task duration from its host results, which
+// carry exact timings in job events.
+func finishEventTask(task *Task) {
+	var end time.Time
+	for _, result := range task.Hosts {
+		if resultEnd := result.StartTime.Add(result.Duration); resultEnd.After(end) {
+			end = resultEnd
+		}
+		if task.StartTime.IsZero() || (!result.StartTime.IsZero() && result.StartTime.Before(task.StartTime)) {
+			task.StartTime = result.StartTime
+		}
+	}
+	if !task.StartTime.IsZero() && end.After(task.StartTime) {
+		task.Duration = end.Sub(task.StartTime)
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRunnerArtifacts(t *testing.T) {
+	dir := "../../testdata/runner-artifacts/1234"
+	if !IsRunnerArtifactDir(dir) || !IsRunnerArtifactDir(dir+"/job_events") || IsRunnerArtifactDir("../../testdata") {
+		t.Fatal("IsRunnerArtifactDir misdetects directories")
+	}
+	tasks, err := ReadRunnerArtifacts(dir)
+	if err != nil {
+		t.Fatalf("ReadRunnerArtifacts: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+
+	install := tasks[0]
+	if install.Description != "nginx : Install nginx package" || install.Role != "nginx" || install.Module != "ansible.builtin.apt" {
+		t.Errorf("unexpected task: %+v", install)
+	}
+	if len(install.Hosts) != 2 || install.Hosts[0].Status != "changed" || install.Hosts[1].Status != "ok" {
+		t.Fatalf("unexpected host results: %+v", install.Hosts)
+	}
+	if install.Hosts[0].Duration != 5500*time.Millisecond || install.Duration != 5510*time.Millisecond {
+		t.Errorf("durations = %v / %v", install.Hosts[0].Duration, install.Duration)
+	}
+	if !strings.Contains(install.Hosts[0].ModuleArgs, `"name":"nginx"`) {
+		t.Errorf("ModuleArgs = %s", install.Hosts[0].ModuleArgs)
+	}
+	if raw, _ := install.LoadRawText(); !strings.HasPrefix(raw, "TASK [nginx : Install nginx package]") || !strings.Contains(raw, "ok: [web02]") {
+		t.Errorf("raw text = %q", raw)
+	}
+
+	render := tasks[1]
+	if render.Status != "unreachable" || render.Hosts[0].Status != "fatal" || len(render.Events) == 0 || render.Events[0].Kind != EventConnection {
+		t.Errorf("unexpected task: %+v", render)
+	}
+	if tasks[2].Status != "skipping" || tasks[2].Role != "" {
+		t.Errorf("unexpected task: %+v", tasks[2])
+	}
+}
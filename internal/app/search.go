@@ -0,0 +1,232 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// Occurrences of the search term in the details panel
+	searchMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#000000")).
+				Background(lipgloss.Color("#FFA500"))
+
+	// The occurrence last jumped to with n/N
+	searchCurrentStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#FF00FF")).
+				Bold(true)
+)
+
+// searchHit is an occurrence of the search term in the raw text of a task,
+// listed under a node of the current view.
+type searchHit struct {
+	nodeID     int
+	taskID     int
+	occurrence int // Index of the occurrence within the task's raw text
+}
+
+// taskSearchHit is an occurrence of the search term in the raw text of a
+// task, found once per task whatever the view.
+type taskSearchHit struct {
+	occurrence int
+	line       int // Line of the log the occurrence is on
+}
+
+// searchableText returns the raw text of node as the details panel shows it,
+// which is what the search looks at.
+func (m *Model) searchableText(node *TreeNode) string {
+	text := m.loadRawText(node)
+	if !m.showColors {
+		text = stripANSI(text)
+	}
+	return m.redact(text)
+}
+
+// runSearch finds all occurrences of term, ignoring case, in the raw text of
+// every task of the log, including tasks hidden by the filter or in collapsed
+// hosts, and jumps to the first one at or after the selected task. An empty
+// term ends the search.
+func (m *Model) runSearch(term string) {
+	m.searchRegex = nil
+	m.searchTaskHits = nil
+	m.searchHits = nil
+	m.searchIndex = -1
+	if term != "" {
+		m.searchRegex = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+		m.searchTaskHits = make(map[int][]taskSearchHit)
+		for i := range m.taskNodes {
+			node := &m.taskNodes[i]
+			text := m.searchableText(node)
+			for occurrence, match := range m.searchRegex.FindAllStringIndex(text, -1) {
+				line := node.Task.StartLine + strings.Count(text[:match[0]], "\n")
+				m.searchTaskHits[node.Task.ID] = append(m.searchTaskHits[node.Task.ID], taskSearchHit{occurrence, line})
+			}
+		}
+		selectedID := 0
+		if len(m.flatNodes) > 0 {
+			selectedID = m.flatNodes[m.selected].node.ID
+		}
+		selected := m.mapSearchHits(selectedID)
+		debugLog.Printf("runSearch() - %d matches for %q", len(m.searchHits), term)
+		if len(m.searchHits) == 0 {
+			m.statusMessage = fmt.Sprintf("No matches for %q", term)
+		} else {
+			if selected < 0 || selected >= len(m.searchHits) {
+				selected = 0
+			}
+			m.gotoSearchHit(selected)
+			return
+		}
+	}
+	m.updateDetailsViewportContent()
+}
+
+// mapSearchHits lists the occurrences found by runSearch under the nodes of
+// the current view, in the order the nodes are listed. In the host view, an
+// occurrence goes to the host whose result it is in, or to the first host of
+// the task. It returns the index of the first hit in or after the node with
+// ID from.
+func (m *Model) mapSearchHits(from int) int {
+	m.searchHits = nil
+	tasks := make(map[int]*Task, len(m.taskNodes))
+	for _, node := range m.taskNodes {
+		tasks[node.Task.ID] = node.Task
+	}
+	first := -1
+	var walk func(nodes []TreeNode)
+	walk = func(nodes []TreeNode) {
+		for i := range nodes {
+			node := &nodes[i]
+			if node.ID == from {
+				first = len(m.searchHits)
+			}
+			if node.Task != nil {
+				for _, hit := range m.searchTaskHits[node.Task.ID] {
+					if !m.hostView || searchHitHost(tasks[node.Task.ID], hit.line) == node.Host {
+						m.searchHits = append(m.searchHits, searchHit{node.ID, node.Task.ID, hit.occurrence})
+					}
+				}
+			}
+			walk(node.Children)
+		}
+	}
+	walk(m.nodes)
+	return first
+}
+
+// searchHitHost returns the host of task whose result is on the given line
+// of the log, or the first host of the task if none is.
+func searchHitHost(task *Task, line int) string {
+	if task == nil || len(task.Hosts) == 0 {
+		return ""
+	}
+	for _, result := range task.Hosts {
+		if line >= result.StartLine && line <= result.EndLine {
+			return inventoryHost(result.Host)
+		}
+	}
+	return inventoryHost(task.Hosts[0].Host)
+}
+
+// remapSearchHits lists the hits of the search under the nodes of a new view
+// or node list, keeping the current hit if it is still listed.
+func (m *Model) remapSearchHits() {
+	if m.searchRegex == nil {
+		return
+	}
+	var current searchHit
+	if m.searchIndex >= 0 && m.searchIndex < len(m.searchHits) {
+		current = m.searchHits[m.searchIndex]
+	}
+	m.mapSearchHits(0)
+	m.searchIndex = slices.IndexFunc(m.searchHits, func(hit searchHit) bool {
+		return hit.taskID == current.taskID && hit.occurrence == current.occurrence
+	})
+}
+
+// nextSearchHit jumps to the next (or previous, for a negative step) search
+// hit, wrapping around at the end of the log.
+func (m *Model) nextSearchHit(step int) {
+	if len(m.searchHits) == 0 {
+		return
+	}
+	m.gotoSearchHit((m.searchIndex + step + len(m.searchHits)) % len(m.searchHits))
+}
+
+// gotoSearchHit selects the task of the search hit at index and scrolls the
+// details panel to it.
+func (m *Model) gotoSearchHit(index int) {
+	m.searchIndex = index
+	// Matches are in the raw text
+	m.detailsTab = tabRaw
+	m.statusMessage = fmt.Sprintf("match %d/%d", index+1, len(m.searchHits))
+	id := m.searchHits[index].nodeID
+	if m.selectNode(id) {
+		return
+	}
+	if m.revealNode(id) {
+		m.statusMessage += " (filter cleared)"
+	}
+	m.selectNode(id)
+}
+
+// revealNode makes the node with the given ID visible in the node list,
+// clearing the filter if it hides the node and expanding the host the node
+// is listed under. It reports whether the filter was cleared.
+func (m *Model) revealNode(id int) bool {
+	contains := func(nodes []TreeNode) bool {
+		return slices.ContainsFunc(nodes, func(n TreeNode) bool {
+			return n.ID == id || slices.ContainsFunc(n.Children, func(c TreeNode) bool { return c.ID == id })
+		})
+	}
+	cleared := false
+	if !contains(m.filteredNodes) && m.filterInput.Value() != "" {
+		debugLog.Printf("revealNode() - Clearing filter %q to show node %d", m.filterInput.Value(), id)
+		m.filterInput.SetValue("")
+		m.filterNodes("")
+		cleared = true
+	}
+	for i := range m.filteredNodes {
+		if contains(m.filteredNodes[i].Children) {
+			m.filteredNodes[i].IsExpanded = true
+		}
+	}
+	m.rebuildFlatNodes()
+	m.updateViewports()
+	return cleared
+}
+
+// currentSearchOccurrence returns the occurrence of the current search hit
+// if it is in node, or -1.
+func (m *Model) currentSearchOccurrence(node *TreeNode) int {
+	if m.searchIndex < 0 || m.searchIndex >= len(m.searchHits) || m.searchHits[m.searchIndex].nodeID != node.ID {
+		return -1
+	}
+	return m.searchHits[m.searchIndex].occurrence
+}
+
+// highlightSearch highlights the matches of regex in text, the match with
+// index current differently, and returns the offset in the result where the
+// current match starts or -1.
+func highlightSearch(text string, regex *regexp.Regexp, current int) (string, int) {
+	var b strings.Builder
+	offset := -1
+	last := 0
+	for i, match := range regex.FindAllStringIndex(text, -1) {
+		b.WriteString(text[last:match[0]])
+		if i == current {
+			offset = b.Len()
+			b.WriteString(searchCurrentStyle.Render(text[match[0]:match[1]]))
+		} else {
+			b.WriteString(searchMatchStyle.Render(text[match[0]:match[1]]))
+		}
+		last = match[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), offset
+}
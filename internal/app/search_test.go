@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile("../../testdata/sample-demo.log")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	want := 0
+	for i := range tasks {
+		text, err := tasks[i].LoadRawText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want += strings.Count(strings.ToLower(text), "ca server")
+	}
+
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.selectNode(5)
+	m.runSearch("CA Server")
+	if len(m.searchHits) != want || want == 0 {
+		t.Fatalf("got %d hits, want %d", len(m.searchHits), want)
+	}
+	// The search starts at the selected task and wraps around
+	first := m.flatNodes[m.selected].node.ID
+	if first < 5 || m.statusMessage != fmt.Sprintf("match 1/%d", want) {
+		t.Errorf("first hit in task %d, status %q", first, m.statusMessage)
+	}
+	m.nextSearchHit(-1)
+	if m.searchIndex != want-1 {
+		t.Errorf("searchIndex = %d after wrapping backwards", m.searchIndex)
+	}
+	m.nextSearchHit(1)
+	if m.searchIndex != 0 || m.flatNodes[m.selected].node.ID != first {
+		t.Errorf("searchIndex = %d after wrapping forwards", m.searchIndex)
+	}
+	if !strings.Contains(m.detailsViewport.View(), "CA server") {
+		t.Errorf("details panel not scrolled to the match:\n%s", m.detailsViewport.View())
+	}
+
+	m.runSearch("no such text")
+	if len(m.searchHits) != 0 || !strings.Contains(m.statusMessage, "No matches") {
+		t.Errorf("unexpected result: %d hits, status %q", len(m.searchHits), m.statusMessage)
+	}
+
+	text, offset := highlightSearch("one two one", regexp.MustCompile("one"), 1)
+	if stripANSI(text) != "one two one" || !strings.HasPrefix(stripANSI(text[offset:]), "one") || offset < len("one two ") {
+		t.Errorf("highlightSearch = %q, %d", text, offset)
+	}
+}
+
+func TestSearchWholeLog(t *testing.T) {
+	log := "TASK [Install] ***\nchanged: [web1]\nok: [web2]\n\n" +
+		"TASK [Configure] ***\n" +
+		`fatal: [web2]: FAILED! => {"changed": false, "msg": "needle"}` + "\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+
+	// Tasks hidden by the filter are searched, and shown by clearing it
+	m.filterInput.SetValue("status:changed")
+	m.filterNodes("status:changed")
+	m.runSearch("needle")
+	if len(m.searchHits) != 1 || m.filterInput.Value() != "" || m.flatNodes[m.selected].node.Task.ID != tasks[1].ID {
+		t.Errorf("%d hits, filter %q, selected %q", len(m.searchHits), m.filterInput.Value(), m.flatNodes[m.selected].node.Name)
+	}
+	if m.statusMessage != "match 1/1 (filter cleared)" {
+		t.Errorf("status %q", m.statusMessage)
+	}
+
+	// Tasks of collapsed hosts are searched, and shown by expanding the host
+	m.runSearch("")
+	m.toggleHostView()
+	m.runSearch("needle")
+	node := m.flatNodes[m.selected].node
+	if len(m.searchHits) != 1 || node.Task == nil || node.Host != "web2" || node.Name != "Configure" {
+		t.Errorf("%d hits, selected %q on %q", len(m.searchHits), node.Name, node.Host)
+	}
+}
+
+func TestSearchHostView(t *testing.T) {
+	log := "TASK [Install needle] ***\nchanged: [web1]\n" +
+		`fatal: [web2]: FAILED! => {"changed": false, "msg": "needle"}` + "\n\n" +
+		"TASK [Configure] ***\nok: [web1]\nok: [web2]\n"
+	parser := NewLogParser(false)
+	defer parser.Close()
+	tasks, err := parser.ParseFile(writeLog(t, log))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	m := NewModel(tasks, false)
+	m.width, m.height = 120, 40
+	m.updateViewports()
+	m.runSearch("needle")
+	if len(m.searchHits) != 2 || m.statusMessage != "match 1/2" {
+		t.Fatalf("%d hits in the task list, status %q", len(m.searchHits), m.statusMessage)
+	}
+	m.nextSearchHit(1)
+
+	// Each task is searched once: the name goes to the first host and the
+	// result to the host that reported it
+	m.toggleHostView()
+	if len(m.searchHits) != 2 || m.searchIndex != 1 {
+		t.Fatalf("%d hits in the host view, current %d", len(m.searchHits), m.searchIndex)
+	}
+	for i, host := range []string{"web1", "web2"} {
+		m.gotoSearchHit(i)
+		if node := m.flatNodes[m.selected].node; node.Host != host || node.Task.ID != tasks[0].ID {
+			t.Errorf("hit %d selects %q on %q, want %s", i, node.Name, node.Host, host)
+		}
+	}
+}
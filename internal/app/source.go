@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// logSource gives random access to the log file tasks were parsed from, so
+// their raw text can be read on demand instead of being kept in memory.
+type logSource struct {
+	path string
+	file *os.File
+}
+
+func openLogSource(path string) (*logSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &logSource{path: path, file: file}, nil
+}
+
+// readRange returns the bytes in [start, end) as a string.
+func (s *logSource) readRange(start, end int64) (string, error) {
+	if end <= start {
+		return "", nil
+	}
+	buf := make([]byte, end-start)
+	n, err := s.file.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading %s: %v", s.path, err)
+	}
+	return string(buf[:n]), nil
+}
+
+func (s *logSource) Close() error {
+	return s.file.Close()
+}
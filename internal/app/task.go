@@ -1,6 +1,8 @@
 package app
 
 import (
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -9,14 +11,143 @@ type Task struct {
 	ID          int
 	Description string
 	StartTime   time.Time
-	Status      string // "ok", "changed", "skipping", "failed"
+	// StartTimeRaw is the timestamp as printed in the log. It is set while
+	// StartTime stays zero when the timestamp could not be parsed.
+	StartTimeRaw string
+	Status       string // "ok", "changed", "skipping", "failed"
+	Host         string
+	Path         string
+	Play         string
+	Role         string
+	Module       string        // Module the task ran, if known
+	Duration     time.Duration // Time from task start until its last host finished
+	Diff         string        // Diff information for the task
+	RawText      string        // Raw text of the task when it is not backed by a log file
+	StartOffset  int64         // Byte offset of the task header in the log file
+	EndOffset    int64         // Byte offset just past the last line of the task
+	StartLine    int           // 1-based line number of the task header
+	EndLine      int           // Line number of the last line of the task
+	Hosts        []HostResult
+	// Attributes holds custom fields set by user-defined parsing rules
+	Attributes map[string]string
+	// Events is the per-host execution timeline from verbose output
+	Events []ExecEvent
+	// Warnings holds the warnings Ansible printed while the task ran
+	Warnings []string
+	// Retries counts the failed attempts of an "until" loop that were retried
+	Retries int
+
+	source *logSource // Log file the offsets refer to, nil if RawText is set
+}
+
+// HostResult is the outcome of a task on a single host, together with the
+// lines of the log file it was reported on.
+type HostResult struct {
 	Host        string
-	Path        string
-	Diff        string // Diff information for the task
-	RawText     string // Raw text of the entire task from the log file
+	Status      string
+	Module      string         // Module that produced the result, from verbose output
+	ModuleArgs  string         // JSON object of the module arguments (invocation.module_args)
+	Messages    []string       // Lines of debug output (msg or var)
+	Command     *CommandResult // Output of a failed command, shell or script task
+	Censored    bool           // Output hidden by Ansible because of no_log
+	Diff        string         // Diff printed with --diff for this host
+	Result      string         // JSON result payload when the task is not backed by a log file
+	StartTime   time.Time
+	Duration    time.Duration
+	StartLine   int
+	EndLine     int
+	StartOffset int64
+	EndOffset   int64
+}
+
+// CommandResult is what a command, shell or script module reported for a
+// failed run.
+type CommandResult struct {
+	Command string
+	RC      int
+	Msg     string
+	Stdout  []string
+	Stderr  []string
+}
+
+// addHostResult records a host result starting at the line last read by
+// reader and returns its index in t.Hosts.
+func (t *Task) addHostResult(status, host string, reader *lineReader) int {
+	t.Hosts = append(t.Hosts, HostResult{
+		Host:        host,
+		Status:      status,
+		StartLine:   reader.lineNo,
+		EndLine:     reader.lineNo,
+		StartOffset: reader.lineStart,
+		EndOffset:   reader.offset,
+	})
+	return len(t.Hosts) - 1
+}
+
+// setAttribute sets a custom attribute of the task.
+func (t *Task) setAttribute(name, value string) {
+	if t.Attributes == nil {
+		t.Attributes = make(map[string]string)
+	}
+	t.Attributes[name] = value
+}
+
+// addWarning records a warning of the task, unless it was seen already in
+// the log text or another result.
+func (t *Task) addWarning(warning string) {
+	warning = strings.TrimSpace(warning)
+	if warning != "" && !slices.Contains(t.Warnings, warning) {
+		t.Warnings = append(t.Warnings, warning)
+	}
+}
+
+// TimeUnparsed reports whether the task has a timestamp that could not be
+// parsed.
+func (t *Task) TimeUnparsed() bool {
+	return t.StartTimeRaw != "" && t.StartTime.IsZero()
+}
+
+// SourcePath returns the path of the log file the task was parsed from, or ""
+// if it doesn't come from a text log.
+func (t *Task) SourcePath() string {
+	if t.source == nil {
+		return ""
+	}
+	return t.source.path
+}
+
+// LoadRawText returns the raw text of the entire task. Tasks parsed from a log
+// file only record their byte range, so the text is read from the file on
+// demand.
+func (t *Task) LoadRawText() (string, error) {
+	if t.source == nil {
+		return t.RawText, nil
+	}
+	return t.source.readRange(t.StartOffset, t.EndOffset)
+}
+
+// LoadResult returns the JSON result payload of the host result at index,
+// or "" if it has none. Like the raw text, payloads of tasks parsed from a
+// log file are read from the file on demand.
+func (t *Task) LoadResult(index int) (string, error) {
+	result := t.Hosts[index]
+	if t.source == nil {
+		return result.Result, nil
+	}
+	text, err := t.source.readRange(result.StartOffset, result.EndOffset)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(stripANSI(text), "\n")
+	start := payloadStart(lines[0])
+	if start == "" {
+		return "", nil
+	}
+	return strings.Join(append([]string{start}, lines[1:]...), "\n"), nil
 }
 
-// DiffSection represents a diff section in a task
+// DiffSection represents a diff section in a task: the changes to one file,
+// with Content holding the hunks of the unified diff
 type DiffSection struct {
 	BeforeFile string
 	AfterFile  string
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// profileTimeRegex matches the timestamp line printed by the profile_tasks
+	// callback in any locale, e.g. "Tuesday 28 October 2025  02:05:23 +0100",
+	// "Dienstag 28. Oktober 2025  02:05:23 +0100" or
+	// "martedì 28 ottobre 2025  02:05:23 +0100".
+	profileTimeRegex = regexp.MustCompile(`^\pL+,? (\d{1,2})\.? (\pL+)\.? (\d{4}) +(\d{1,2}):(\d{2}):(\d{2})(?: ([+-]\d{4}))?`)
+
+	// isoTimeRegex matches an ISO 8601 / RFC 3339 timestamp at the start of a
+	// line, e.g. "2025-10-28T02:05:23.123+01:00" or "2025-10-28 02:05:23,123".
+	isoTimeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)(?:\s|$)`)
+
+	// profileElapsedRegex matches the time profile_tasks reports for the
+	// previous task in parentheses after the timestamp, e.g. "(0:00:01.762)".
+	profileElapsedRegex = regexp.MustCompile(`\((\d+):(\d{2}):(\d{2}(?:\.\d+)?)\)`)
+)
+
+// isoTimeLayouts are tried in order to parse a timestamp matched by isoTimeRegex
+// (with a decimal comma already replaced by a dot).
+var isoTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// monthNames maps lower-case month names and abbreviations in the locales
+// Ansible controllers commonly run with to month numbers.
+var monthNames = map[string]time.Month{}
+
+func init() {
+	locales := [][12]string{
+		// English
+		{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"},
+		{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+		// German
+		{"januar", "februar", "märz", "april", "mai", "juni", "juli", "august", "september", "oktober", "november", "dezember"},
+		{"jan", "feb", "mär", "apr", "mai", "jun", "jul", "aug", "sep", "okt", "nov", "dez"},
+		// Italian
+		{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		// French
+		{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		// Spanish
+		{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		// Portuguese
+		{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		// Dutch
+		{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	}
+	for _, names := range locales {
+		for i, name := range names {
+			monthNames[name] = time.Month(i + 1)
+		}
+	}
+}
+
+// parseTimestampLine recognises a line consisting of a task timestamp. It
+// returns ok if the line has the shape of a timestamp, and the parsed time
+// unless the timestamp could not be interpreted (e.g. an unknown month name),
+// in which case t is zero. raw is the timestamp text as found in the log.
+func parseTimestampLine(line string) (t time.Time, raw string, ok bool) {
+	if m := profileTimeRegex.FindStringSubmatch(line); m != nil {
+		raw = strings.TrimSpace(m[0])
+		month, known := monthNames[strings.ToLower(m[2])]
+		if !known {
+			return time.Time{}, raw, true
+		}
+		day, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[3])
+		hour, _ := strconv.Atoi(m[4])
+		minute, _ := strconv.Atoi(m[5])
+		second, _ := strconv.Atoi(m[6])
+		loc := time.UTC
+		if m[7] != "" {
+			if zone, err := time.Parse("-0700", m[7]); err == nil {
+				loc = zone.Location()
+			}
+		}
+		t = time.Date(year, month, day, hour, minute, second, 0, loc)
+		// time.Date normalises out-of-range values; treat those as unparsable
+		if t.Day() != day || t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+			return time.Time{}, raw, true
+		}
+		return t, raw, true
+	}
+
+	if m := isoTimeRegex.FindStringSubmatch(line); m != nil {
+		raw = m[1]
+		value := strings.Replace(raw, ",", ".", 1)
+		for _, layout := range isoTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, raw, true
+			}
+		}
+		return time.Time{}, raw, true
+	}
+
+	return time.Time{}, "", false
+}
+
+// profileElapsed returns the duration of the previous task printed on a
+// profile_tasks timestamp line.
+func profileElapsed(line string) (time.Duration, bool) {
+	m := profileElapsedRegex.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), true
+}
+
+// fillDurations sets the duration of tasks that have none from the start of
+// the next task.
+func fillDurations(tasks []Task) {
+	for i := 0; i+1 < len(tasks); i++ {
+		start, next := tasks[i].StartTime, tasks[i+1].StartTime
+		if tasks[i].Duration == 0 && !start.IsZero() && next.After(start) {
+			tasks[i].Duration = next.Sub(start)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -54,24 +63,43 @@ var (
 				Background(lipgloss.Color("#25A065")).
 				Padding(0, 1)
 
+	detailsTabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#888888")).
+			Padding(0, 1)
+
 	// Help text style
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#626262")).
 			Italic(true)
+
+	// Filter query error style
+	filterErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF0000"))
+
+	// Characters of task names matched by the regex and fuzzy filters
+	filterMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFA500")).
+				Bold(true).
+				Underline(true)
 )
 
 // TreeNode represents a node in our tree structure
 type TreeNode struct {
-	ID          int
-	Name        string
-	Description string
-	StartTime   time.Time
-	Status      string
-	Host        string
-	Path        string
-	Diff        string
-	RawText     string
-	IsExpanded  bool
+	ID         int
+	Name       string
+	StartTime  time.Time
+	Status     string
+	Host       string
+	Path       string
+	Diff       string
+	Task       *Task // Source task, used to load the raw text on demand
+	IsExpanded bool
+	// ShowLongLines disables truncation of gigantic lines in the details panel
+	ShowLongLines bool
+	// Children are the tasks of a host in the host view, shown when the
+	// host is expanded
+	Children []TreeNode
+	Recap    string // Task counts of a host in the host view
 }
 
 // flatNode represents a node in the flattened tree for display
@@ -80,72 +108,203 @@ type flatNode struct {
 	depth int
 }
 
-// Convert tasks to tree nodes
+// Convert tasks to tree nodes. Nodes point at the tasks instead of copying
+// their raw text, which is only loaded for the node shown in the details panel.
 func convertTasksToNodes(tasks []Task) []TreeNode {
 	nodes := make([]TreeNode, len(tasks))
-	for i, task := range tasks {
+	for i := range tasks {
+		task := &tasks[i]
 		nodes[i] = TreeNode{
-			ID:          task.ID,
-			Name:        task.Description,
-			Description: task.RawText,
-			StartTime:   task.StartTime,
-			Status:      task.Status,
-			Host:        task.Host,
-			Path:        task.Path,
-			Diff:        task.Diff,
-			RawText:     task.RawText,
-			IsExpanded:  false,
+			ID:         task.ID,
+			Name:       task.Description,
+			StartTime:  task.StartTime,
+			Status:     task.Status,
+			Host:       task.Host,
+			Path:       task.Path,
+			Diff:       task.Diff,
+			Task:       task,
+			IsExpanded: false,
 		}
 	}
 	return nodes
 }
 
 // fuzzyMatch performs a simple fuzzy match: all characters in pattern must
-// appear in order in s (case-insensitive). This is cheap and good for
-// interactive filtering.
-func fuzzyMatch(pattern, s string) bool {
-	pattern = strings.ToLower(pattern)
-	s = strings.ToLower(s)
-	if pattern == "" {
-		return true
-	}
+// appear in order in s (case-insensitive, spaces in pattern are ignored). The
+// score rewards consecutive characters and characters at word starts, and
+// positions are the rune indexes of the matched characters in s. This is cheap
+// and good for interactive filtering.
+func fuzzyMatch(pattern, s string) (score int, positions []int, ok bool) {
+	runes := []rune(s)
 	si := 0
-	for _, pr := range pattern {
-		idx := strings.IndexRune(s[si:], pr)
-		if idx < 0 {
-			return false
+	for _, pr := range strings.ToLower(pattern) {
+		if pr == ' ' {
+			continue
+		}
+		for si < len(runes) && unicode.ToLower(runes[si]) != pr {
+			si++
 		}
-		si += idx + 1
-		if si >= len(s) && pr != rune(pattern[len(pattern)-1]) {
-			// if we've reached the end of s but there are still pattern runes
-			// left (and the last rune wasn't matched), it's a fail
-			// (the normal IndexRune check above handles most cases)
+		if si == len(runes) {
+			return 0, nil, false
 		}
+		score++
+		switch {
+		case len(positions) > 0 && positions[len(positions)-1] == si-1:
+			score += 5
+		case si == 0 || !unicode.IsLetter(runes[si-1]) && !unicode.IsDigit(runes[si-1]):
+			score += 3
+		}
+		positions = append(positions, si)
+		si++
+	}
+	// Prefer matches that start early
+	if len(positions) > 0 {
+		score -= positions[0] / 4
 	}
-	return true
+	return score, positions, true
+}
+
+// maxDetailsLineLen is the number of bytes of a single line shown in the
+// details panel before it is truncated. Wrapping multi-megabyte lines is very
+// slow and makes the panel unusable, so they are cut unless expanded with "x".
+const maxDetailsLineLen = 2000
+
+// diffScrollStep is the number of columns h and l scroll side-by-side diffs.
+const diffScrollStep = 8
+
+// truncateLongLines shortens every line of text longer than limit bytes and
+// appends a marker telling the user how much was hidden.
+func truncateLongLines(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if len(line) <= limit {
+			continue
+		}
+		cut := limit
+		// Don't split a multi-byte rune or a colour escape sequence
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		cut = ansiSafeCut(line, cut)
+		kept := line[:cut]
+		if strings.Contains(kept, "\x1b") {
+			// Don't let a colour bleed into the marker
+			kept += "\x1b[0m"
+		}
+		lines[i] = fmt.Sprintf("%s … [+%d bytes, x: expand]", kept, len(line)-cut)
+	}
+	return strings.Join(lines, "\n")
 }
 
 // Model represents the TUI state (PoC)
 type Model struct {
-	nodes             []TreeNode
-	filteredNodes     []TreeNode
-	flatNodes         []flatNode // All visible nodes in a flat list
-	selected          int
-	width             int
-	height            int
-	loaded            bool
-	err               error
-	quitting          bool
-	nodesViewport     viewport.Model
-	detailsViewport   viewport.Model
-	helpTextViewport  viewport.Model
-	filterInput       textinput.Model
-	showingFilter     bool
-	expandedNodeCount int
-	expandedNodeSize  int
-	helpText          string
+	nodes               []TreeNode // Nodes of the current view, taskNodes or hostNodes
+	taskNodes           []TreeNode
+	hostNodes           []TreeNode // Built when the host view is first shown
+	hostView            bool
+	showDashboard       bool // Show the run summary instead of the task list
+	dashboardItems      []dashboardItem
+	dashboardSelected   int
+	dashboardViewport   viewport.Model
+	showKeys            bool // Show every key instead of the task list
+	keysViewport        viewport.Model
+	filteredNodes       []TreeNode
+	flatNodes           []flatNode // All visible nodes in a flat list
+	selected            int
+	width               int
+	height              int
+	loaded              bool
+	err                 error
+	quitting            bool
+	nodesViewport       viewport.Model
+	detailsViewport     viewport.Model
+	helpTextViewport    viewport.Model
+	filterInput         textinput.Model
+	showingFilter       bool
+	filterErr           error // Error in the filter query, shown below the input
+	filterMode          filterMode
+	matchPositions      map[int][]int   // Node ID -> rune indexes of the name matched by the filter
+	attributeNames      map[string]bool // Custom attributes of the tasks, usable in filter queries
+	searchInput         textinput.Model
+	showingSearch       bool
+	searchRegex         *regexp.Regexp          // Search term matched in raw task text, nil without a search
+	searchTaskHits      map[int][]taskSearchHit // Task ID -> occurrences of the search term
+	searchHits          []searchHit             // searchTaskHits under the nodes of the current view
+	searchIndex         int                     // Index into searchHits of the match jumped to last
+	detailsTab          detailsTab
+	resultTree          *jsonTree // Result tree of the task last shown in the Result tab
+	resultTreeTask      *Task     // Task resultTree belongs to, a host's copy in the host view
+	resultFocused       bool      // Keys move in the result tree instead of the task list
+	resultFilterInput   textinput.Model
+	showingResultFilter bool
+	sideBySide          bool // Show diffs side by side instead of unified
+	diffScroll          int  // Columns side-by-side diffs are scrolled to the right
+	expandedNodeCount   int
+	expandedNodeSize    int
+	helpText            string
+	rawTextNodeID       int    // ID of the node whose raw text is cached
+	rawText             string // Raw text of the last node shown in details
+	statusMessage       string // One-off message shown in front of the help text
+	showColors          bool   // Render ANSI colours of the raw text instead of stripping them
+	redactor            *Redactor
+	showSecrets         bool // Show details without redacting secrets
+	// follow reads tasks appended to the log in follow mode, nil otherwise
+	follow func() ([]Task, bool, error)
+}
+
+// redact hides secrets in text unless they are to be shown.
+func (m *Model) redact(text string) string {
+	if m.redactor == nil || m.showSecrets {
+		return text
+	}
+	return m.redactor.Redact(text)
+}
+
+// SetRedactor replaces the redactor hiding secrets in the details panel,
+// which by default only knows the built-in patterns.
+func (m *Model) SetRedactor(redactor *Redactor) {
+	m.redactor = redactor
+	m.resultTree = nil
+	m.updateDetailsViewportContent()
 }
 
+// filterMode selects how the filter input is interpreted.
+type filterMode int
+
+const (
+	filterModeQuery     filterMode = iota // Query language, see parseFilterQuery
+	filterModeSubstring                   // Plain text, without query syntax
+	filterModeRegex                       // Regular expression
+	filterModeFuzzy                       // Fuzzy match on the task name, best matches first
+	filterModeCount
+)
+
+// prompt returns the filter input prompt showing the mode.
+func (f filterMode) prompt() string {
+	return [...]string{"query", "text", "regex", "fuzzy"}[f] + " > "
+}
+
+// detailsTab is a view of the selected task in the details panel.
+type detailsTab int
+
+const (
+	tabRaw     detailsTab = iota // Raw task output with parsed sections
+	tabSummary                   // Status, host counts, timing and location of the task
+	tabHosts                     // Table of the task's host results
+	tabDiff                      // Diffs of the task per host
+	tabResult                    // Result payloads of the task as a JSON tree
+	detailsTabCount
+)
+
+// detailsTabNames are the titles of the details tabs.
+var detailsTabNames = [...]string{"Raw", "Summary", "Hosts", "Diff", "Result"}
+
+// viewerClosedMsg is sent when the pager or editor opened on the log exits.
+type viewerClosedMsg struct{ err error }
+
 func NewModel(tasks []Task, enableDebug bool) Model {
 	setupLogger(enableDebug)
 	debugLog.Printf("NewModel() - Received %d tasks", len(tasks))
@@ -162,14 +321,46 @@ func NewModel(tasks []Task, enableDebug bool) Model {
 	helpVp := viewport.New(0, 0)
 	helpVp.HighPerformanceRendering = false
 
+	dashboardVp := viewport.New(0, 0)
+	dashboardVp.HighPerformanceRendering = false
+
+	keysVp := viewport.New(0, 0)
+	keysVp.HighPerformanceRendering = false
+	keysVp.SetContent(renderKeyHelp())
+
+	redactor, _ := NewRedactor(nil)
+
+	taskPointers := make([]*Task, len(tasks))
+	for i := range tasks {
+		taskPointers[i] = &tasks[i]
+	}
+
+	si := textinput.New()
+	si.Placeholder = "Search task output..."
+	si.Prompt = "search ? "
+	si.CharLimit = 100
+	si.Width = 30
+
+	ri := textinput.New()
+	ri.Placeholder = "Filter result keys..."
+	ri.Prompt = "keys > "
+	ri.CharLimit = 100
+	ri.Width = 30
+
 	ti := textinput.New()
-	ti.Placeholder = "Filter..."
-	ti.Prompt = "> "
+	ti.Placeholder = "Filter... (tab: change mode)"
+	ti.Prompt = filterModeQuery.prompt()
 	ti.CharLimit = 100
 	ti.Width = 30
 
 	m := Model{
 		nodes:             nodes,
+		taskNodes:         nodes,
+		dashboardItems:    buildDashboard(taskPointers),
+		attributeNames:    attributeNames(taskPointers),
+		dashboardViewport: dashboardVp,
+		showDashboard:     len(tasks) > 0,
+		keysViewport:      keysVp,
 		selected:          0,
 		width:             80,
 		height:            24,
@@ -178,9 +369,13 @@ func NewModel(tasks []Task, enableDebug bool) Model {
 		detailsViewport:   detailsVp,
 		helpTextViewport:  helpVp,
 		filterInput:       ti,
-		helpText:          "j/k, up/down: move • ctrl+j/k: scroll details • /: filter • g/G: go to first/last line • q: quit",
+		helpText:          shortHelpText,
 		expandedNodeCount: 0,
 		expandedNodeSize:  4,
+		redactor:          redactor,
+		searchInput:       si,
+		searchIndex:       -1,
+		resultFilterInput: ri,
 	}
 
 	// Initialize the filtered nodes and build flat nodes
@@ -196,6 +391,9 @@ func NewModel(tasks []Task, enableDebug bool) Model {
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.follow != nil {
+		return tea.Batch(textinput.Blink, followTick())
+	}
 	return textinput.Blink
 }
 
@@ -210,28 +408,133 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewports()
 		return m, nil
 
+	case viewerClosedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Viewer failed: %v", msg.err)
+		}
+		return m, nil
+
+	case followTickMsg:
+		// The next tick is only scheduled once the log was read
+		return m, m.readFollow()
+
+	case followMsg:
+		m.updateFollow(msg)
+		return m, followTick()
+
 	case tea.KeyMsg:
+		m.statusMessage = ""
+		if m.showingSearch {
+			switch msg.String() {
+			case "esc":
+				m.showingSearch = false
+				m.searchInput.Blur()
+				m.searchInput.SetValue("")
+				m.runSearch("")
+				m.updateViewports()
+				return m, nil
+			case "enter":
+				m.showingSearch = false
+				m.searchInput.Blur()
+				m.updateViewports()
+				m.runSearch(m.searchInput.Value())
+				return m, nil
+			default:
+				m.searchInput, cmd = m.searchInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+		if m.showKeys {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			case "down", "j", "up", "k", "pgdown", "pgup":
+				m.keysViewport, cmd = m.keysViewport.Update(msg)
+				return m, cmd
+			}
+			// Any other key goes back to the screen help was opened from
+			m.showKeys = false
+			return m, nil
+		}
+
+		if m.showDashboard {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			case "down", "j":
+				m.dashboardSelected = min(m.dashboardSelected+1, len(m.dashboardItems)-1)
+				m.updateDashboardContent()
+			case "up", "k":
+				m.dashboardSelected = max(m.dashboardSelected-1, 0)
+				m.updateDashboardContent()
+			case "enter", " ":
+				m.openDashboardItem(m.dashboardItems[m.dashboardSelected])
+			case "esc", "D":
+				m.openDashboardItem(dashboardItem{})
+			case "f1":
+				m.showKeys = true
+			}
+			return m, nil
+		}
+
+		if m.showingResultFilter {
+			switch msg.String() {
+			case "esc", "enter":
+				m.showingResultFilter = false
+				m.resultFilterInput.Blur()
+				if msg.String() == "esc" {
+					m.resultFilterInput.SetValue("")
+					m.resultTree.setFilter("")
+				}
+				m.updateViewports()
+				return m, nil
+			default:
+				m.resultFilterInput, cmd = m.resultFilterInput.Update(msg)
+				m.resultTree.setFilter(m.resultFilterInput.Value())
+				m.updateDetailsViewportContent()
+				return m, cmd
+			}
+		}
+
+		if m.resultFocused && m.detailsTab == tabResult {
+			if handled, cmd := m.updateResultTree(msg); handled {
+				return m, cmd
+			}
+		}
+
 		if m.showingFilter {
 			switch msg.String() {
 			case "esc":
 				m.showingFilter = false
 				m.filterInput.Blur()
 				m.filterInput.SetValue("")
-				m.applyFilter("")
+				m.filterNodes("")
 				m.updateViewports()
 				return m, nil
 			case "enter":
-				m.showingFilter = false
-				m.filterInput.Blur()
-				// apply final filter and close input
-				m.applyFilter(m.filterInput.Value())
+				// apply final filter and close input, unless the query is
+				// invalid and the error needs to stay visible
+				m.filterNodes(m.filterInput.Value())
+				if m.filterErr == nil {
+					m.showingFilter = false
+					m.filterInput.Blur()
+				}
 				m.updateViewports()
 				return m, nil
+			case "tab":
+				m.filterMode = (m.filterMode + 1) % filterModeCount
+				m.filterInput.Prompt = m.filterMode.prompt()
+				m.filterNodes(m.filterInput.Value())
+				m.setNodeListContentFrom(strings.TrimSpace(m.renderNodeList()))
+				return m, nil
 			default:
 				// update the input model first
 				m.filterInput, cmd = m.filterInput.Update(msg)
 				// apply filter as-you-type
-				m.applyFilter(m.filterInput.Value())
+				m.filterNodes(m.filterInput.Value())
 				// update viewport content without full resize (reset to top)
 				m.setNodeListContentFrom(strings.TrimSpace(m.renderNodeList()))
 				return m, cmd
@@ -242,10 +545,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "f1":
+			m.showKeys = true
+			return m, nil
 		case "/":
 			m.showingFilter = true
 			m.filterInput.Focus()
 			return m, textinput.Blink
+		case "?":
+			m.showingSearch = true
+			m.searchInput.Focus()
+			return m, textinput.Blink
+		case "[", "]":
+			step := 1
+			if msg.String() == "[" {
+				step = int(detailsTabCount) - 1
+			}
+			m.detailsTab = (m.detailsTab + detailsTab(step)) % detailsTabCount
+			m.detailsViewport.GotoTop()
+			m.updateDetailsViewportContent()
+		case "1", "2", "3", "4", "5":
+			m.detailsTab = detailsTab(msg.String()[0] - '1')
+			m.detailsViewport.GotoTop()
+			m.updateDetailsViewportContent()
+		case "tab":
+			// Focus the result tree, so that the movement keys browse it
+			m.detailsTab = tabResult
+			m.resultFocused = true
+			m.detailsViewport.GotoTop()
+			m.updateDetailsViewportContent()
+		case "H":
+			m.toggleHostView()
+		case "D":
+			m.showDashboard = true
+			m.updateDashboardContent()
+		case "v":
+			m.sideBySide = !m.sideBySide
+			m.diffScroll = 0
+			m.detailsTab = tabDiff
+			m.detailsViewport.GotoTop()
+			m.updateDetailsViewportContent()
+		case "h", "left", "l", "right":
+			if m.sideBySide && m.detailsTab == tabDiff {
+				if key := msg.String(); key == "h" || key == "left" {
+					m.diffScroll = max(m.diffScroll-diffScrollStep, 0)
+				} else {
+					m.diffScroll += diffScrollStep
+				}
+				m.updateDetailsViewportContent()
+			}
+		case "f", "F", "c", "C", "u", "U", "w", "W", "r", "R":
+			m.jumpKey(msg.String())
+		case "n":
+			m.nextSearchHit(1)
+		case "N":
+			m.nextSearchHit(-1)
 		case "up", "k":
 			if m.selected > 0 {
 				m.selected--
@@ -298,6 +652,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.nodesViewport.SetYOffset(m.selected - m.nodesViewport.Height + (m.expandedNodeCount * m.expandedNodeSize) + 1)
 				}
 			}
+		case "x":
+			if len(m.flatNodes) > 0 {
+				node := m.flatNodes[m.selected].node
+				node.ShowLongLines = !node.ShowLongLines
+				m.updateDetailsViewportContent()
+			}
+		case "a":
+			m.showColors = !m.showColors
+			m.updateDetailsViewportContent()
+		case "ctrl+r":
+			m.showSecrets = !m.showSecrets
+			// The result tree holds redacted values
+			m.resultTree = nil
+			if m.showSecrets {
+				m.statusMessage = "Showing secrets"
+			} else {
+				m.statusMessage = "Hiding secrets"
+			}
+			m.updateDetailsViewportContent()
+		case "o", "O":
+			if len(m.flatNodes) > 0 {
+				return m, m.openInViewer(m.flatNodes[m.selected].node, msg.String() == "O")
+			}
 		case "pgup", "ctrl+u":
 			m.detailsViewport, cmd = m.detailsViewport.Update(msg)
 			cmds = append(cmds, cmd)
@@ -322,6 +699,9 @@ func (m *Model) flattenNodes(nodes []TreeNode, depth int) {
 	for i := range nodes {
 		node := &nodes[i]
 		m.flatNodes = append(m.flatNodes, flatNode{node: node, depth: depth})
+		if node.IsExpanded && len(node.Children) > 0 {
+			m.flattenNodes(node.Children, depth+1)
+		}
 	}
 }
 
@@ -334,7 +714,8 @@ func (m *Model) rebuildFlatNodes() {
 	// is preserved on the model (don't mutate it from render functions).
 	m.expandedNodeCount = 0
 	for _, fn := range m.flatNodes {
-		if fn.node.IsExpanded {
+		// Expanded hosts show their tasks instead of inline details
+		if fn.node.IsExpanded && fn.node.Task != nil {
 			m.expandedNodeCount++
 		}
 	}
@@ -350,7 +731,6 @@ func (m *Model) updateViewports() {
 	// Fixed sizes
 	const (
 		headerHeight      = 2
-		helpHeight        = 1
 		detailsMinHeight  = 15
 		minNodesHeight    = 3
 		horizontalPadding = 4
@@ -359,6 +739,9 @@ func (m *Model) updateViewports() {
 	// Calculate available space
 	debugLog.Printf("updateViewports() - Calculating viewports with expandedNodeCount: %d", m.expandedNodeCount)
 
+	// The help line wraps in narrow windows and with long status messages
+	helpHeight := lipgloss.Height(m.renderHelpLine())
+
 	// Calculate base available height
 	baseHeight := m.height - headerHeight - helpHeight - 4
 
@@ -396,6 +779,14 @@ func (m *Model) updateViewports() {
 	m.updateDetailsViewportContent()
 
 	m.helpTextViewport.SetContent(m.renderHelpLine())
+
+	// The dashboard takes the place of the task list and details panel
+	m.dashboardViewport.Width = m.width - horizontalPadding
+	m.dashboardViewport.Height = max(m.height-headerHeight-4-helpHeight, 1)
+	m.updateDashboardContent()
+
+	m.keysViewport.Width = m.width - horizontalPadding
+	m.keysViewport.Height = max(m.height-headerHeight-4-1, 1)
 }
 
 // assignViewportDimensions sets width/height on viewports and syncs input width.
@@ -404,12 +795,14 @@ func (m *Model) assignViewportDimensions(horizontalPadding, nodesViewportHeight,
 	m.nodesViewport.Height = nodesViewportHeight
 	m.detailsViewport.Width = m.width - horizontalPadding
 
-	// Keep filter input width in sync with viewports
+	// Keep filter and search input width in sync with viewports
 	if m.nodesViewport.Width >= 2 {
 		m.filterInput.Width = m.nodesViewport.Width - 2
 	} else {
 		m.filterInput.Width = m.nodesViewport.Width
 	}
+	m.searchInput.Width = m.filterInput.Width
+	m.resultFilterInput.Width = m.filterInput.Width
 
 	// Set details viewport height (account for title and padding)
 	detailsTitleHeight := lipgloss.Height(m.renderDetailsPanelTitle())
@@ -462,24 +855,89 @@ func (m *Model) updateDetailsViewportContent() {
 		return
 	}
 	selectedNode := m.flatNodes[m.selected].node
-
-	// Create content with title
-	replacer := strings.NewReplacer("\\n", "\n", "\\t", "\t", "\\\"", "\"")
-	detailsContent := fmt.Sprintf("Item: %s\n\n%s",
-		selectedNode.Name,
-		replacer.Replace(selectedNode.Description))
+	if selectedNode.Task == nil {
+		m.detailsViewport.SetContent(lipgloss.NewStyle().Width(m.detailsViewport.Width - 4).Render(renderHostSummary(selectedNode)))
+		return
+	}
 
 	// Calculate the available width for content, accounting for borders and padding
 	contentWidth := m.detailsViewport.Width - 4 // -4 for left and right padding/borders
-
 	// Style the content with fixed width to enable proper scrolling
-	styledContent := lipgloss.NewStyle().
-		Width(contentWidth).
-		Render(detailsContent)
+	contentStyle := lipgloss.NewStyle().Width(contentWidth)
+
+	if m.detailsTab == tabDiff {
+		render := renderDiff
+		if m.sideBySide {
+			render = func(diff string) string { return renderSideBySide(diff, contentWidth, m.diffScroll) }
+		}
+		content := fmt.Sprintf("Item: %s\n\n%s", selectedNode.Name, renderDiffTab(selectedNode.Task, m.redact, render))
+		m.detailsViewport.SetContent(contentStyle.Render(content))
+		return
+	}
+	if m.detailsTab == tabSummary || m.detailsTab == tabHosts {
+		render := renderSummaryTab
+		if m.detailsTab == tabHosts {
+			render = renderHostsTab
+		}
+		content := fmt.Sprintf("Item: %s\n\n%s", selectedNode.Name, render(selectedNode.Task, m.redact))
+		m.detailsViewport.SetContent(contentStyle.Render(content))
+		return
+	}
+	if m.detailsTab == tabResult {
+		tree := m.currentResultTree(selectedNode)
+		focused := m.resultFocused
+		content := fmt.Sprintf("Item: %s\n\n%s", selectedNode.Name, tree.render(contentWidth, focused))
+		m.detailsViewport.SetContent(contentStyle.Render(content))
+		if focused {
+			// Keep the cursor in view, below the two header lines
+			line := tree.cursor + 2
+			if line < m.detailsViewport.YOffset {
+				m.detailsViewport.SetYOffset(line)
+			} else if line >= m.detailsViewport.YOffset+m.detailsViewport.Height {
+				m.detailsViewport.SetYOffset(line - m.detailsViewport.Height + 1)
+			}
+		}
+		return
+	}
+
+	// Create content with title
+	rawText := m.searchableText(selectedNode)
+	// Matches of a search may be anywhere in long lines
+	if !selectedNode.ShowLongLines && m.searchRegex == nil {
+		rawText = truncateLongLines(rawText, maxDetailsLineLen)
+	}
+	header := m.redact(fmt.Sprintf("Item: %s\nStart Time: %s%s\n%s%s%s%s%s%s%s\n",
+		selectedNode.Name,
+		formatStartTime(selectedNode),
+		formatDuration(selectedNode.Task),
+		renderAttributes(selectedNode.Task),
+		renderSourceLines(selectedNode.Task),
+		renderWarnings(selectedNode.Task),
+		renderFailures(selectedNode.Task),
+		renderMessages(selectedNode.Task),
+		renderArguments(selectedNode.Task),
+		renderEvents(selectedNode.Task)))
+	currentMatch := -1
+	if m.searchRegex != nil {
+		rawText, currentMatch = highlightSearch(rawText, m.searchRegex, m.currentSearchOccurrence(selectedNode))
+	}
+	// Diffs are coloured unless the log's own colours are shown
+	styledRawText := rawText
+	if !m.showColors {
+		styledRawText = colorizeDiffs(rawText)
+	}
+	styledContent := contentStyle.Render(header + styledRawText)
 
 	debugLog.Printf("updateDetailsViewportContent() - Details content length: %d lines", strings.Count(styledContent, "\n")+1)
 
 	m.detailsViewport.SetContent(styledContent)
+	if currentMatch >= 0 {
+		// Scroll to the wrapped line the current search match is on; the
+		// diff colours don't change how lines wrap
+		line := lipgloss.Height(contentStyle.Render(header+rawText[:currentMatch])) - 1
+		m.detailsViewport.SetYOffset(max(line-2, 0))
+		return
+	}
 	// Preserve scroll position unless selected item changed
 	currentYOffset := m.detailsViewport.YOffset
 	if currentYOffset == 0 {
@@ -487,6 +945,453 @@ func (m *Model) updateDetailsViewportContent() {
 	}
 }
 
+// selectNode selects the node with the given ID in the node list, scrolling
+// the list to it, and reports whether the node is in the list.
+func (m *Model) selectNode(id int) bool {
+	for i, fn := range m.flatNodes {
+		if fn.node.ID == id {
+			m.selectIndex(i)
+			return true
+		}
+	}
+	return false
+}
+
+// selectIndex selects the node at index i of the node list, scrolling the
+// list to it.
+func (m *Model) selectIndex(i int) {
+	m.selected = i
+	if m.selected < m.nodesViewport.YOffset {
+		m.nodesViewport.SetYOffset(m.selected)
+	} else if m.selected+(m.expandedNodeCount*m.expandedNodeSize) >= m.nodesViewport.YOffset+m.nodesViewport.Height {
+		m.nodesViewport.SetYOffset(m.selected - m.nodesViewport.Height + (m.expandedNodeCount * m.expandedNodeSize) + 1)
+	}
+	m.setNodeListContentPreserve(m.renderNodeList())
+	m.updateDetailsViewportContent()
+}
+
+// formatStartTime formats the start time of node, making clear when the log
+// has no timestamp or one that could not be parsed.
+func formatStartTime(node *TreeNode) string {
+	if node.Task != nil && node.Task.TimeUnparsed() {
+		return fmt.Sprintf("unparsed (%s)", node.Task.StartTimeRaw)
+	}
+	if node.StartTime.IsZero() {
+		return "unknown"
+	}
+	return node.StartTime.Format("2006-01-02 15:04:05")
+}
+
+// renderAttributes lists the custom attributes set on task by user-defined
+// parsing rules, sorted by name.
+func renderAttributes(task *Task) string {
+	if task == nil || len(task.Attributes) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(task.Attributes))
+	for name := range task.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, task.Attributes[name])
+	}
+	return b.String()
+}
+
+// renderArguments renders the module and module arguments of each host
+// result of task that reported them, one argument per line.
+func renderArguments(task *Task) string {
+	if task == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, result := range task.Hosts {
+		if result.ModuleArgs == "" {
+			continue
+		}
+		var args map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(result.ModuleArgs), &args); err != nil {
+			continue
+		}
+		module := result.Module
+		if module == "" {
+			module = task.Module
+		}
+		if module != "" {
+			fmt.Fprintf(&b, "  %s (%s):\n", result.Host, module)
+		} else {
+			fmt.Fprintf(&b, "  %s:\n", result.Host)
+		}
+		names := make([]string, 0, len(args))
+		for name := range args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := string(args[name])
+			// Show strings without JSON quoting and escapes
+			var s string
+			if json.Unmarshal(args[name], &s) == nil {
+				value = s
+			}
+			fmt.Fprintf(&b, "    %s: %s\n", name, value)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\nArguments:\n" + b.String()
+}
+
+// hostStatusOrder is the order in which host counts are listed.
+var hostStatusOrder = []string{"ok", "changed", "skipping", "failed", "fatal", "unreachable"}
+
+// renderSummaryTab renders the status, host counts, timing and location of
+// task, passing texts from the log through redact.
+func renderSummaryTab(task *Task, redact func(string) string) string {
+	if task == nil {
+		return ""
+	}
+	counts := make(map[string]int)
+	var statuses []string
+	for _, result := range task.Hosts {
+		if counts[result.Status] == 0 && !slices.Contains(hostStatusOrder, result.Status) {
+			statuses = append(statuses, result.Status)
+		}
+		counts[result.Status]++
+	}
+	var parts []string
+	for _, status := range append(slices.Clone(hostStatusOrder), statuses...) {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	hosts := fmt.Sprint(len(task.Hosts))
+	if len(parts) > 0 {
+		hosts += " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	duration := "unknown"
+	if task.Duration > 0 {
+		duration = task.Duration.Round(time.Millisecond).String()
+	}
+	started := "unknown"
+	if task.TimeUnparsed() {
+		started = fmt.Sprintf("unparsed (%s)", task.StartTimeRaw)
+	} else if !task.StartTime.IsZero() {
+		started = task.StartTime.Format("2006-01-02 15:04:05")
+	}
+	source := task.SourcePath()
+	if task.StartLine > 0 {
+		source = strings.TrimPrefix(source+", "+formatLineRange(task.StartLine, task.EndLine), ", ")
+	}
+
+	warnings := ""
+	if len(task.Warnings) > 0 || task.Retries > 0 {
+		warnings = fmt.Sprintf("%d (%d retries)", len(task.Warnings), task.Retries)
+	}
+
+	var b strings.Builder
+	for _, field := range []struct{ name, value string }{
+		{"Status", statusStyleFor(task.Status).Render(strings.ToUpper(task.Status))},
+		{"Hosts", hosts},
+		{"Duration", duration},
+		{"Started", started},
+		{"Play", redact(task.Play)},
+		{"Role", redact(task.Role)},
+		{"Path", redact(task.Path)},
+		{"Module", task.Module},
+		{"Warnings", warnings},
+		{"Source", source},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%-9s %s\n", field.name+":", field.value)
+		}
+	}
+	return b.String()
+}
+
+// renderHostsTab renders a table of the host results of task with a note on
+// each, passing texts from the log through redact.
+func renderHostsTab(task *Task, redact func(string) string) string {
+	if task == nil || len(task.Hosts) == 0 {
+		return "No host results recorded for this task.\n"
+	}
+	header := []string{"HOST", "STATUS", "MODULE", "DURATION", "LINES", "NOTE"}
+	rows := [][]string{header}
+	for _, result := range task.Hosts {
+		duration := ""
+		if result.Duration > 0 {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		lines := ""
+		if result.StartLine > 0 {
+			lines = strings.TrimPrefix(strings.TrimPrefix(formatLineRange(result.StartLine, result.EndLine), "lines "), "line ")
+		}
+		rows = append(rows, []string{result.Host, result.Status, result.Module, duration, lines, hostNote(result, redact)})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	var b strings.Builder
+	for r, row := range rows {
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			switch {
+			case r == 0:
+				cell = diffHeaderStyle.Render(cell)
+			case i == 1:
+				cell = statusStyleFor(cell).Render(cell)
+			}
+			if i == len(row)-1 {
+				padding = ""
+			}
+			b.WriteString(cell + padding)
+			if i < len(row)-1 {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// hostNote summarises what is notable about a host result: the return code
+// of a failed command, the first message, a diff or hidden output.
+func hostNote(result HostResult, redact func(string) string) string {
+	var notes []string
+	if result.Censored {
+		notes = append(notes, "output hidden by no_log")
+	}
+	if result.Command != nil {
+		note := fmt.Sprintf("rc=%d", result.Command.RC)
+		if result.Command.Msg != "" {
+			note += " " + redact(result.Command.Msg)
+		}
+		notes = append(notes, note)
+	}
+	if len(result.Messages) > 0 {
+		notes = append(notes, redact(result.Messages[0]))
+	}
+	if result.Diff != "" {
+		notes = append(notes, "diff")
+	}
+	return strings.Join(notes, "; ")
+}
+
+// renderDiffTab renders the diffs of task per host with render, passing them
+// through redact before styling them.
+func renderDiffTab(task *Task, redact, render func(string) string) string {
+	if task == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, result := range task.Hosts {
+		if result.Diff == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%s):\n%s\n", result.Host, result.Status, render(redact(result.Diff)))
+	}
+	// Diffs that no host result followed
+	if b.Len() == 0 && task.Diff != "" {
+		b.WriteString(render(redact(task.Diff)))
+	}
+	if b.Len() == 0 {
+		return "No diff recorded for this task. Run ansible-playbook with --diff to record changes.\n"
+	}
+	return b.String()
+}
+
+// renderFailures renders the command, return code and numbered output of
+// each host on which a command of task failed.
+func renderFailures(task *Task) string {
+	if task == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, result := range task.Hosts {
+		command := result.Command
+		if command == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s (rc=%d):\n", result.Host, command.RC)
+		if command.Command != "" {
+			fmt.Fprintf(&b, "    $ %s\n", command.Command)
+		}
+		if command.Msg != "" {
+			fmt.Fprintf(&b, "    %s\n", command.Msg)
+		}
+		for _, stream := range []struct {
+			name  string
+			lines []string
+		}{{"stdout", command.Stdout}, {"stderr", command.Stderr}} {
+			if len(stream.lines) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "    %s:\n", stream.name)
+			for i, line := range stream.lines {
+				fmt.Fprintf(&b, "    %5d  %s\n", i+1, line)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\nFailure:\n" + b.String()
+}
+
+// renderWarnings renders the warnings of task and how often it was retried.
+func renderWarnings(task *Task) string {
+	if task == nil || len(task.Warnings) == 0 && task.Retries == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if task.Retries > 0 {
+		fmt.Fprintf(&b, "\nRetries: %d\n", task.Retries)
+	}
+	if len(task.Warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, warning := range task.Warnings {
+			fmt.Fprintf(&b, "  %s\n", warning)
+		}
+	}
+	return b.String()
+}
+
+// renderMessages renders the debug output of each host of task as text.
+func renderMessages(task *Task) string {
+	if task == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, result := range task.Hosts {
+		if len(result.Messages) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s:\n", result.Host)
+		for _, line := range result.Messages {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\nMessages:\n" + b.String()
+}
+
+// renderEvents renders the execution timeline of task parsed from verbose
+// output, one event per line.
+func renderEvents(task *Task) string {
+	if task == nil || len(task.Events) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nEvents:\n")
+	for _, event := range task.Events {
+		line := ""
+		if event.Line > 0 {
+			line = fmt.Sprintf("L%d", event.Line)
+		}
+		fmt.Fprintf(&b, "  %-7s %-20s %-11s %s\n", line, event.Host, event.Kind, event.Detail)
+	}
+	return b.String()
+}
+
+// formatDuration formats the duration of task for display after its start
+// time, or returns "" if it is unknown.
+func formatDuration(task *Task) string {
+	if task == nil || task.Duration <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (took %s)", task.Duration.Round(time.Millisecond))
+}
+
+// formatLineRange formats a range of log file lines for display.
+func formatLineRange(start, end int) string {
+	if end <= start {
+		return fmt.Sprintf("line %d", start)
+	}
+	return fmt.Sprintf("lines %d–%d", start, end)
+}
+
+// renderSourceLines lists where the task and each of its host results are
+// found in the log file, along with the host's status and timing if known.
+func renderSourceLines(task *Task) string {
+	if task == nil {
+		return ""
+	}
+	var b strings.Builder
+	if task.StartLine > 0 {
+		fmt.Fprintf(&b, "Source: %s, %s\n", task.SourcePath(), formatLineRange(task.StartLine, task.EndLine))
+	}
+	for _, result := range task.Hosts {
+		location := ""
+		if result.StartLine > 0 {
+			location = formatLineRange(result.StartLine, result.EndLine)
+		}
+		if result.Duration > 0 {
+			location = strings.TrimSpace(location + " " + result.Duration.Round(time.Millisecond).String())
+		}
+		if result.Censored {
+			location = strings.TrimSpace(location + " (output hidden by no_log)")
+		}
+		fmt.Fprintf(&b, "  %-30s %-11s %s\n", result.Host, result.Status, location)
+	}
+	return b.String()
+}
+
+// openInViewer opens the log file of node's task at the task's first line, in
+// $PAGER or, if useEditor is set, in $EDITOR. Both are passed "+<line>", which
+// less, more, vi, nano and emacs all understand.
+func (m *Model) openInViewer(node *TreeNode, useEditor bool) tea.Cmd {
+	if node.Task == nil || node.Task.SourcePath() == "" {
+		m.statusMessage = "Task has no log file to open"
+		return nil
+	}
+	program, fallback := os.Getenv("PAGER"), "less"
+	if useEditor {
+		program, fallback = os.Getenv("EDITOR"), "vi"
+	}
+	args := strings.Fields(program)
+	if len(args) == 0 {
+		args = []string{fallback}
+	}
+	lineArg := fmt.Sprintf("+%d", node.Task.StartLine)
+	if filepath.Base(args[0]) == "less" {
+		// For less "+" introduces a command; "g" goes to the line
+		lineArg += "g"
+	}
+	args = append(args, lineArg, node.Task.SourcePath())
+	debugLog.Printf("openInViewer() - Running %v", args)
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return viewerClosedMsg{err: err} })
+}
+
+// loadRawText returns the raw text of node, reading it from the log file only
+// when a different node than last time is shown.
+func (m *Model) loadRawText(node *TreeNode) string {
+	if node.Task == nil {
+		return ""
+	}
+	if m.rawTextNodeID == node.ID {
+		return m.rawText
+	}
+	text, err := node.Task.LoadRawText()
+	if err != nil {
+		debugLog.Printf("loadRawText() - Error loading raw text for node %d: %v", node.ID, err)
+		return fmt.Sprintf("Error loading raw text: %v", err)
+	}
+	m.rawTextNodeID = node.ID
+	m.rawText = text
+	return text
+}
+
 func (m Model) View() string {
 	if m.quitting {
 		return ""
@@ -497,11 +1402,35 @@ func (m Model) View() string {
 		Width(m.width).
 		Render("Ansible Logs TUI")
 
+	if m.showKeys {
+		help := helpStyle.Width(m.width - 4).Render("j/k: scroll • any other key: close")
+		return lipgloss.JoinVertical(lipgloss.Left,
+			header,
+			appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, m.keysViewport.View(), help)),
+		)
+	}
+
+	if m.showDashboard {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			header,
+			appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, m.dashboardViewport.View(), m.renderHelpLine())),
+		)
+	}
+
 	// Build main content area: optional filter input, nodes viewport, details panel, help
 	var mainSections []string
+	if m.showingSearch {
+		mainSections = append(mainSections, m.searchInput.View())
+	}
+	if m.showingResultFilter {
+		mainSections = append(mainSections, m.resultFilterInput.View())
+	}
 	if m.showingFilter {
 		// show filter input above the node list
 		mainSections = append(mainSections, m.filterInput.View())
+		if m.filterErr != nil {
+			mainSections = append(mainSections, filterErrorStyle.Render("  "+m.filterErr.Error()))
+		}
 	}
 
 	// Add nodes viewport
@@ -570,10 +1499,28 @@ func (m *Model) renderHelpLine() string {
 	debugLog.Printf("renderHelpLine() - Rendering help line with %d expanded nodes", m.expandedNodeCount)
 	viewportContent := m.helpTextViewport.View()
 	contentText := fmt.Sprintf("%s", m.helpText)
+	if m.statusMessage != "" {
+		contentText = m.statusMessage + " • " + contentText
+	}
 	content := lipgloss.JoinVertical(lipgloss.Left, contentText, viewportContent)
 	return helpStyle.Width(m.width - 4).Render(content)
 }
 
+// statusStyleFor returns the style of a task or host status.
+func statusStyleFor(status string) lipgloss.Style {
+	switch status {
+	case "ok":
+		return statusOkStyle
+	case "changed":
+		return statusChangedStyle
+	case "skipping":
+		return statusSkippingStyle
+	case "failed", "fatal", "unreachable":
+		return statusFailedStyle
+	}
+	return statusUnknownStyle
+}
+
 func (m Model) renderNodeList() string {
 	var b strings.Builder
 	debugLog.Printf("renderNodeList() - Rendering %d nodes, selected index: %d", len(m.flatNodes), m.selected)
@@ -584,22 +1531,7 @@ func (m Model) renderNodeList() string {
 
 		status := strings.ToUpper(node.Status)
 
-		// Style based on status
-		var statusStyle lipgloss.Style
-		switch node.Status {
-		case "ok":
-			statusStyle = statusOkStyle
-		case "changed":
-			statusStyle = statusChangedStyle
-		case "skipping":
-			statusStyle = statusSkippingStyle
-		case "failed", "fatal":
-			statusStyle = statusFailedStyle
-		default:
-			statusStyle = statusUnknownStyle
-		}
-
-		statusStr := statusStyle.Render(status)
+		statusStr := statusStyleFor(node.Status).Render(status)
 
 		indicator := " "
 		if node.IsExpanded {
@@ -607,7 +1539,17 @@ func (m Model) renderNodeList() string {
 		} else {
 			indicator = "▶"
 		}
-		line := fmt.Sprintf("%s%s [%d] %s - [%s]", indent, indicator, node.ID, node.Name, statusStr)
+		name := node.Name
+		if positions := m.matchPositions[node.ID]; len(positions) > 0 {
+			name = highlightRunes(name, positions)
+		}
+		var line string
+		if node.Task != nil {
+			line = fmt.Sprintf("%s%s [%d] %s - [%s]", indent, indicator, node.Task.ID, name, statusStr)
+		} else {
+			// A host of the host view
+			line = fmt.Sprintf("%s%s %s - %s - [%s]", indent, indicator, name, node.Recap, statusStr)
+		}
 		if i == m.selected {
 			debugLog.Printf("renderNodeList() - Highlighting line %d: %s", i, line)
 			selectedLineStyle := selectedStyle.Copy().Width(m.width - 4)
@@ -615,12 +1557,15 @@ func (m Model) renderNodeList() string {
 		}
 		b.WriteString(line + "\n")
 		// If the node is expanded, show its description as an indented detail
-		if node.IsExpanded && strings.TrimSpace(node.Description) != "" {
+		if node.IsExpanded && node.Task != nil {
 			descLine := fmt.Sprintf("Host: %s\nPath: %s\nStart Time: %s\nStatus: %s",
 				node.Host,
 				node.Path,
-				node.StartTime.Format("2006-01-02 15:04:05"),
+				formatStartTime(node),
 				node.Status)
+			if attributes := renderAttributes(node.Task); attributes != "" {
+				descLine += "\n" + strings.TrimSuffix(attributes, "\n")
+			}
 
 			b.WriteString(inlineDetailStyle.Render(descLine) + "\n")
 		}
@@ -633,8 +1578,39 @@ func (m Model) renderNodeList() string {
 	return content
 }
 
+// highlightRunes renders the runes of s at the given ascending indexes with
+// filterMatchStyle.
+func highlightRunes(s string, positions []int) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if len(positions) == 0 || positions[0] != i {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		// Render runs of consecutive matches at once
+		run := 0
+		for run < len(positions) && positions[run] == i+run {
+			run++
+		}
+		b.WriteString(filterMatchStyle.Render(string(runes[i : i+run])))
+		positions = positions[run:]
+		i += run
+	}
+	return b.String()
+}
+
 func (m Model) renderDetailsPanelTitle() string {
-	return detailsTitleStyle.Render("Details")
+	tabs := make([]string, len(detailsTabNames))
+	for i, name := range detailsTabNames {
+		if detailsTab(i) == m.detailsTab {
+			tabs[i] = detailsTitleStyle.Render(name)
+		} else {
+			tabs[i] = detailsTabStyle.Render(name)
+		}
+	}
+	return strings.Join(tabs, " ")
 }
 
 func (m Model) renderDetailsPanel() string {
@@ -644,26 +1620,62 @@ func (m Model) renderDetailsPanel() string {
 	return detailsPanelStyle.Width(m.width - 4).Render(panelContent)
 }
 
+// applyFilter shows the nodes matching the filter query term, see
+// parseFilterQuery.
 func (m *Model) applyFilter(term string) {
-	term = strings.ToLower(term)
-	if term == "" {
+	m.matchPositions = nil
+	query, err := parseFilterQuery(term, m.attributeNames)
+	m.filterErr = err
+	if err != nil {
+		// Keep showing the last valid result while the query is incomplete
+		return
+	}
+	if strings.TrimSpace(term) == "" {
 		m.filteredNodes = m.nodes
 	} else {
-		var filtered []TreeNode
-		for _, n := range m.nodes {
-			// Check against all possible fields
-			if strings.Contains(strings.ToLower(n.Name), term) ||
-				strings.Contains(strings.ToLower(n.Status), term) ||
-				strings.Contains(strings.ToLower(n.Host), term) ||
-				strings.Contains(strings.ToLower(n.Path), term) ||
-				strings.Contains(n.StartTime.Format("2006-01-02 15:04:05"), term) ||
-				strings.Contains(n.StartTime.Format("2006-01-02"), term) ||
-				strings.Contains(n.StartTime.Format("15:04:05"), term) {
-				filtered = append(filtered, n)
+		m.filteredNodes = keepMatching(m.nodes, func(n *TreeNode) bool {
+			return n.Task != nil && query.match(n.Task)
+		})
+	}
+	m.filterApplied()
+}
+
+// keepMatching returns the nodes for which match is true. Hosts of the host
+// view are kept with those of their tasks that match, if any.
+func keepMatching(nodes []TreeNode, match func(n *TreeNode) bool) []TreeNode {
+	var kept []TreeNode
+	for _, n := range nodes {
+		if len(n.Children) > 0 {
+			if n.Children = keepMatching(n.Children, match); len(n.Children) > 0 {
+				kept = append(kept, n)
 			}
+			continue
+		}
+		if match(&n) {
+			kept = append(kept, n)
 		}
-		m.filteredNodes = filtered
 	}
+	return kept
+}
+
+// filterNodes filters the nodes by term, interpreted according to the
+// current filter mode.
+func (m *Model) filterNodes(term string) {
+	switch m.filterMode {
+	case filterModeSubstring:
+		// Matches and highlights like a regex, for text taken literally
+		m.applyRegexFilter(regexp.QuoteMeta(term))
+	case filterModeRegex:
+		m.applyRegexFilter(term)
+	case filterModeFuzzy:
+		m.applyFuzzyFilter(term)
+	default:
+		m.applyFilter(term)
+	}
+}
+
+// filterApplied updates the node list once m.filteredNodes has changed.
+func (m *Model) filterApplied() {
 	m.rebuildFlatNodes()
 
 	// Reset selection and viewport to top when applying a filter
@@ -676,31 +1688,110 @@ func (m *Model) applyFilter(term string) {
 	m.nodesViewport.GotoTop()
 }
 
+// matchAttributes reports whether any custom attribute of task, formatted as
+// "name=value", contains the lower-case term.
+func matchAttributes(task *Task, term string) bool {
+	if task == nil {
+		return false
+	}
+	for name, value := range task.Attributes {
+		if strings.Contains(strings.ToLower(name+"="+value), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModuleArgs reports whether the module arguments of any host result of
+// task contain the lower-case term.
+func matchModuleArgs(task *Task, term string) bool {
+	if task == nil {
+		return false
+	}
+	for _, result := range task.Hosts {
+		if strings.Contains(strings.ToLower(result.ModuleArgs), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFuzzyFilter shows the nodes whose name fuzzily matches term, best
+// matches first.
 func (m *Model) applyFuzzyFilter(term string) {
+	m.filterErr = nil
+	m.matchPositions = nil
 	term = strings.TrimSpace(term)
 	if term == "" {
 		m.filteredNodes = m.nodes
 	} else {
-		var filtered []TreeNode
-		for _, n := range m.nodes {
-			if fuzzyMatch(term, n.Name) ||
-				fuzzyMatch(term, n.Status) ||
-				fuzzyMatch(term, n.Host) ||
-				fuzzyMatch(term, n.Path) ||
-				fuzzyMatch(term, n.StartTime.Format("2006-01-02 15:04:05")) {
-				filtered = append(filtered, n)
+		scores := make(map[int]int)
+		m.matchPositions = make(map[int][]int)
+		filtered := keepMatching(m.nodes, func(n *TreeNode) bool {
+			score, positions, ok := fuzzyMatch(term, n.Name)
+			if ok {
+				scores[n.ID] = score
+				m.matchPositions[n.ID] = positions
 			}
+			return ok
+		})
+		// The tasks of a host stay in the order they ran
+		if !m.hostView {
+			sort.SliceStable(filtered, func(i, j int) bool { return scores[filtered[i].ID] > scores[filtered[j].ID] })
 		}
 		m.filteredNodes = filtered
 	}
-	m.rebuildFlatNodes()
+	m.filterApplied()
+}
 
-	// Reset selection and viewport to top when applying a filter
-	if len(m.flatNodes) == 0 {
-		m.selected = 0
-	} else if m.selected >= len(m.flatNodes) {
-		m.selected = len(m.flatNodes) - 1
+// applyRegexFilter shows the nodes with a field matching the regular
+// expression term, ignoring case.
+func (m *Model) applyRegexFilter(term string) {
+	m.matchPositions = nil
+	regex, err := regexp.Compile("(?i)" + term)
+	m.filterErr = err
+	if err != nil {
+		return
 	}
-	m.nodesViewport.SetContent(strings.TrimSpace(m.renderNodeList()))
-	m.nodesViewport.GotoTop()
+	if term == "" {
+		m.filteredNodes = m.nodes
+	} else {
+		m.matchPositions = make(map[int][]int)
+		m.filteredNodes = keepMatching(m.nodes, func(n *TreeNode) bool {
+			if loc := regex.FindStringIndex(n.Name); loc != nil {
+				// Highlight the match, given as byte offsets, by rune index
+				first := utf8.RuneCountInString(n.Name[:loc[0]])
+				m.matchPositions[n.ID] = nil
+				for i := range utf8.RuneCountInString(n.Name[loc[0]:loc[1]]) {
+					m.matchPositions[n.ID] = append(m.matchPositions[n.ID], first+i)
+				}
+				return true
+			}
+			return regexMatchesTask(regex, n.Task)
+		})
+	}
+	m.filterApplied()
+}
+
+// regexMatchesTask reports whether regex matches a field of task other than
+// its name.
+func regexMatchesTask(regex *regexp.Regexp, task *Task) bool {
+	if task == nil {
+		return false
+	}
+	if regex.MatchString(task.Status) || regex.MatchString(task.Host) || regex.MatchString(task.Path) || regex.MatchString(task.Diff) ||
+		regex.MatchString(task.StartTime.Format("2006-01-02 15:04:05")) {
+		return true
+	}
+	for name, value := range task.Attributes {
+		if regex.MatchString(name + "=" + value) {
+			return true
+		}
+	}
+	for _, result := range task.Hosts {
+		if regex.MatchString(result.ModuleArgs) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kinds of execution events parsed from verbose (-vvv and above) output
+const (
+	EventConnection = "connection"  // ESTABLISH ... CONNECTION
+	EventTransfer   = "transfer"    // PUT/FETCH of files, sftp/scp runs
+	EventExec       = "exec"        // commands run on the host
+	EventExecResult = "exec_result" // return code and output of a command
+	EventBecome     = "become"      // commands run through sudo/su/...
+	EventModule     = "module"      // module file used for the task
+	EventModuleArgs = "module_args"
+	EventOther      = "other" // any other <host> line
+)
+
+var (
+	// verboseRegex matches the per-host lines printed with -vvv, e.g.
+	// "<web01> ESTABLISH SSH CONNECTION FOR USER: deploy"
+	verboseRegex = regexp.MustCompile(`^<([^<>\s]+)> (.*)$`)
+
+	// execResultRegex matches the "(rc, b'stdout', b'stderr')" line logged
+	// after each command
+	execResultRegex = regexp.MustCompile(`^\(-?\d+, b?['"]`)
+
+	// moduleFileRegex matches "Using module file /.../modules/command.py"
+	moduleFileRegex = regexp.MustCompile(`^Using module file (\S+)`)
+)
+
+// ExecEvent is a step of a task's execution on a host, as reported by
+// verbose output.
+type ExecEvent struct {
+	Host   string
+	Kind   string
+	Detail string
+	Line   int // Line number in the log file
+}
+
+// addEvent appends an execution event to the task.
+func (t *Task) addEvent(host, kind, detail string, line int) {
+	t.Events = append(t.Events, ExecEvent{Host: host, Kind: kind, Detail: detail, Line: line})
+}
+
+// classifyVerbose returns the event kind of the message of a <host> line.
+func classifyVerbose(message string) string {
+	switch {
+	case strings.HasPrefix(message, "ESTABLISH "):
+		return EventConnection
+	case strings.HasPrefix(message, "PUT "), strings.HasPrefix(message, "FETCH "):
+		return EventTransfer
+	case strings.Contains(message, "BECOME-SUCCESS-"):
+		return EventBecome
+	case strings.HasPrefix(message, "SSH: EXEC sftp "), strings.HasPrefix(message, "SSH: EXEC scp "):
+		return EventTransfer
+	case strings.HasPrefix(message, "SSH: EXEC "), strings.HasPrefix(message, "EXEC "):
+		return EventExec
+	case execResultRegex.MatchString(message):
+		return EventExecResult
+	}
+	return EventOther
+}